@@ -42,7 +42,7 @@ func main() {
 	// Initialize and Start Garbage Collector
 	// Create a cancellable context for the GC so we can shut it down cleanly
 	gcCtx, gcCancel := context.WithCancel(context.Background())
-	gc := worker.NewGarbageCollector(database, st)
+	gc := worker.NewGarbageCollector(database, st, redisClient)
 
 	// Start it in the background, checking every 10 seconds (useful for dev, might use 1m or 5m in prod)
 	go gc.Start(gcCtx, 10*time.Second)
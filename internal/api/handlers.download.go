@@ -2,32 +2,38 @@ package api
 
 import (
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/sumanthd032/codedrop/internal/db"
 )
 
-// handleGetDropMetadata returns info about the file (name, size, salt)
+// handleGetDropMetadata returns info about the file (encrypted metadata, size, salt)
 // The CLI needs this *before* it starts downloading to set up decryption.
 func (s *Server) handleGetDropMetadata() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		dropID := chi.URLParam(r, "id")
+		grantID := r.URL.Query().Get("grant")
 
 		var resp GetDropMetadataResponse
 		var expiresAt time.Time
 		var maxDownloads int 
+		var grantsOnly bool
+		var notBefore sql.NullTime
 
 		// 1. Fetch metadata from Postgres (added max_downloads to the query)
 		query := `
-			SELECT file_name, file_size, encryption_salt, expires_at, max_downloads 
+			SELECT metadata, file_size, encryption_salt, algorithm, COALESCE(key_id, ''), wrapped_keys, ` + db.ExpiresAt + `, max_downloads, manifest, grants_only, not_before
 			FROM drops WHERE id = $1`
 		
 		err := s.DB.QueryRow(query, dropID).Scan(
-			&resp.FileName, &resp.FileSize, &resp.EncryptionSalt, &expiresAt, &maxDownloads,
+			&resp.Metadata, &resp.FileSize, &resp.EncryptionSalt, &resp.Algorithm, &resp.KeyID, &resp.WrappedKeys, &expiresAt, &maxDownloads, &resp.Manifest, &grantsOnly, &notBefore,
 		)
 
 		if err != nil {
@@ -35,14 +41,42 @@ func (s *Server) handleGetDropMetadata() http.HandlerFunc {
 			return
 		}
 
-		// 2. Check Time Expiry
+		// 2. Check Time Expiry (including the idle and grace rules)
 		if time.Now().After(expiresAt) {
 			http.Error(w, "Drop has expired", http.StatusGone)
 			return
 		}
 
-		// 3. Atomic download count check using Redis
-		allowed, err := s.Cache.IncrementAndCheck(r.Context(), dropID, maxDownloads)
+		// An embargoed drop doesn't open (or count a view) before its time
+		if notYetAvailable(w, notBefore) {
+			return
+		}
+
+		// An empty request slot, or a drop whose upload is still running, has
+		// nothing to download yet (the manifest seals it); don't spend a view on it
+		if len(resp.Metadata) == 0 || resp.Manifest == nil {
+			http.Error(w, "Drop is still waiting for its upload", http.StatusConflict)
+			return
+		}
+
+		// 3. Atomic download count check using Redis. A drop pushed with
+		// --recipients counts each person's grant separately.
+		var allowed bool
+		var remaining int
+		switch {
+		case grantID != "":
+			g, ok := s.lookupGrant(w, dropID, grantID)
+			if !ok {
+				return
+			}
+			allowed, remaining, err = s.Cache.GrantIncrementAndCheck(r.Context(), grantID, g.MaxDownloads)
+			resp.GrantWrappedKey = g.WrappedKey // Only handed out with a counted download
+		case grantsOnly:
+			http.Error(w, "This drop can only be downloaded through its access grants", http.StatusForbidden)
+			return
+		default:
+			allowed, remaining, err = s.Cache.IncrementAndCheck(r.Context(), dropID, maxDownloads)
+		}
 		if err != nil {
 			http.Error(w, "Internal server error checking limits", http.StatusInternalServerError)
 			return
@@ -51,6 +85,22 @@ func (s *Server) handleGetDropMetadata() http.HandlerFunc {
 			http.Error(w, "Download limit reached", http.StatusGone)
 			return
 		}
+		// The last permitted download arms burn-after-reading. A grants-only drop
+		// is used up once all of its live grants are.
+		if remaining == 0 {
+			if grantID == "" {
+				s.markExhausted(r.Context(), dropID, maxDownloads)
+			} else if exhausted, started, err := s.grantsExhausted(r.Context(), dropID); err == nil && exhausted {
+				s.markExhausted(r.Context(), dropID, started)
+			}
+		}
+
+		// The download restarts the idle clock. The grace period starts when the
+		// first download completes, in handleDownloadChunk.
+		s.DB.Exec("UPDATE drops SET last_download_at = NOW() WHERE id = $1", dropID)
+		if grantID != "" {
+			s.DB.Exec("UPDATE grants SET last_download_at = NOW() WHERE id = $1", grantID)
+		}
 
 		// 4. Get chunk count
 		err = s.DB.QueryRow("SELECT COUNT(*) FROM chunks WHERE drop_id = $1", dropID).Scan(&resp.ChunkCount)
@@ -59,28 +109,122 @@ func (s *Server) handleGetDropMetadata() http.HandlerFunc {
 			return
 		}
 
+		// 5. The chunks are only served to the download counted here
+		resp.DownloadToken, err = randomToken()
+		if err == nil {
+			err = s.Cache.IssueDownloadToken(r.Context(), resp.DownloadToken, dropID, grantID, downloadTokenTTL)
+		}
+		if err != nil {
+			http.Error(w, "Failed to issue download token", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// handleGetDropInfo returns the same metadata as handleGetDropMetadata, but is
+// read-only: it never touches the download counter, so `codedrop info` is free.
+// With ?grant= it reports that grant's downloads. It never hands out a grant's
+// wrapped key or a download token; those come only with a counted download.
+func (s *Server) handleGetDropInfo() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dropID := chi.URLParam(r, "id")
+
+		var resp DropInfoResponse
+		var notBefore sql.NullTime
+		query := `
+			SELECT metadata, encryption_salt, algorithm, COALESCE(key_id, ''), wrapped_keys, file_size, ` + db.ExpiresAt + `, max_downloads, manifest, not_before
+			FROM drops WHERE id = $1`
+
+		err := s.DB.QueryRow(query, dropID).Scan(
+			&resp.Metadata, &resp.EncryptionSalt, &resp.Algorithm, &resp.KeyID, &resp.WrappedKeys, &resp.FileSize, &resp.ExpiresAt, &resp.MaxDownloads, &resp.Manifest, &notBefore,
+		)
+		if err != nil {
+			http.Error(w, "Drop not found", http.StatusNotFound)
+			return
+		}
+		resp.NotBefore = nullTime(notBefore) // Info stays readable during an embargo and says when it ends
+
+		if time.Now().After(resp.ExpiresAt) {
+			http.Error(w, "Drop has expired", http.StatusGone)
+			return
+		}
+
+		if len(resp.Metadata) == 0 {
+			http.Error(w, "Drop is still waiting for its upload", http.StatusConflict)
+			return
+		}
+
+		// A grant link gets its own download count
+		if grantID := r.URL.Query().Get("grant"); grantID != "" {
+			g, ok := s.lookupGrant(w, dropID, grantID)
+			if !ok {
+				return
+			}
+			resp.MaxDownloads = g.MaxDownloads
+			resp.Downloads, err = s.Cache.GrantDownloadCount(r.Context(), grantID)
+		} else {
+			resp.Downloads, err = s.Cache.DownloadCount(r.Context(), dropID)
+		}
+		if err != nil {
+			http.Error(w, "Internal server error checking limits", http.StatusInternalServerError)
+			return
+		}
+		if resp.Downloads >= resp.MaxDownloads {
+			http.Error(w, "Download limit reached", http.StatusGone)
+			return
+		}
+
+		err = s.DB.QueryRow("SELECT COUNT(*) FROM chunks WHERE drop_id = $1", dropID).Scan(&resp.ChunkCount)
+		if err != nil {
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(resp)
 	}
 }
 
-// handleDownloadChunk retrieves a specific piece of binary data
+// handleDownloadChunk retrieves a specific piece of binary data. Only a
+// download counted by handleGetDropMetadata may fetch it, with the token it
+// was issued in the X-Download-Token header.
 func (s *Server) handleDownloadChunk() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		dropID := chi.URLParam(r, "id")
 		chunkIndex := chi.URLParam(r, "chunkIndex")
 
-		// 1. Look up the hash from Postgres
+		// 1. Look up the hash from Postgres, with the drop's embargo, expiry and
+		// whether this is its last chunk (which completes a download)
 		var chunkHash string
+		var notBefore sql.NullTime
+		var expiresAt time.Time
+		var burnAfter sql.NullInt64
+		var isLast bool
 		err := s.DB.QueryRow(`
-			SELECT chunk_hash FROM chunks 
-			WHERE drop_id = $1 AND chunk_index = $2`, 
-			dropID, chunkIndex).Scan(&chunkHash)
+			SELECT c.chunk_hash, d.not_before, `+db.ExpiresAt+`, d.burn_after,
+				NOT EXISTS (SELECT 1 FROM chunks WHERE drop_id = c.drop_id AND chunk_index > c.chunk_index)
+			FROM chunks c
+			JOIN drops d ON d.id = c.drop_id
+			WHERE c.drop_id = $1 AND c.chunk_index = $2`, 
+			dropID, chunkIndex).Scan(&chunkHash, &notBefore, &expiresAt, &burnAfter, &isLast)
 			
 		if err != nil {
 			http.Error(w, "Chunk metadata not found", http.StatusNotFound)
 			return
 		}
+		if notYetAvailable(w, notBefore) {
+			return
+		}
+		if time.Now().After(expiresAt) {
+			http.Error(w, "Drop has expired", http.StatusGone)
+			return
+		}
+		if !s.authorizeDownload(w, r, dropID) {
+			return
+		}
 
 		// 2. Construct CAS S3 Key
 		key := fmt.Sprintf("chunks/%s", chunkHash)
@@ -101,6 +245,59 @@ func (s *Server) handleDownloadChunk() http.HandlerFunc {
 		}
 
 		w.Header().Set("Content-Type", "application/octet-stream")
-		w.Write(data)
+		if _, err := w.Write(data); err != nil {
+			return
+		}
+
+		// 5. A download served to the end starts the grace period (push --grace),
+		// and may be the one that burns the drop
+		if isLast {
+			s.DB.Exec("UPDATE drops SET first_download_at = COALESCE(first_download_at, NOW()) WHERE id = $1", dropID)
+			s.completeDownload(r.Context(), dropID, r.Header.Get("X-Download-Token"), int(burnAfter.Int64))
+		}
+	}
+}
+
+// downloadTokenTTL is how long a download counted by the metadata endpoint may
+// go on fetching chunks
+const downloadTokenTTL = time.Hour
+
+// authorizeDownload checks the download token sent with a chunk request. It
+// must have been issued for this drop, and a grant link must not have been
+// revoked since. Writes the error response itself.
+func (s *Server) authorizeDownload(w http.ResponseWriter, r *http.Request, dropID string) bool {
+	token := r.Header.Get("X-Download-Token")
+	if token == "" {
+		http.Error(w, "Missing X-Download-Token header", http.StatusUnauthorized)
+		return false
+	}
+
+	tokenDrop, grantID, err := s.Cache.DownloadToken(r.Context(), token)
+	if err != nil {
+		http.Error(w, "Internal server error checking limits", http.StatusInternalServerError)
+		return false
+	}
+	if tokenDrop != dropID {
+		http.Error(w, "Invalid or expired download token", http.StatusForbidden)
+		return false
+	}
+	if grantID != "" {
+		if _, ok := s.lookupGrant(w, dropID, grantID); !ok {
+			return false
+		}
 	}
-}
\ No newline at end of file
+	return true
+}
+
+// notYetAvailable answers 425 Too Early for an embargoed drop (push --not-before)
+// that hasn't opened yet. X-Not-Before carries the time for clients and
+// Retry-After how many seconds are left.
+func notYetAvailable(w http.ResponseWriter, notBefore sql.NullTime) bool {
+	if !notBefore.Valid || !time.Now().Before(notBefore.Time) {
+		return false
+	}
+	w.Header().Set("X-Not-Before", notBefore.Time.UTC().Format(time.RFC3339Nano))
+	w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(notBefore.Time)/time.Second)+1))
+	http.Error(w, "Drop is not available until "+notBefore.Time.UTC().Format(time.RFC3339), http.StatusTooEarly)
+	return true
+}
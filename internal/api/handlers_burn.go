@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"log"
+)
+
+// Burn after reading. The metadata endpoint notices when it hands out a
+// drop's last permitted download and records how many downloads were started
+// in burn_after. The chunk endpoint counts a download as completed when its
+// last chunk is served with the download's token, once per token; when that
+// count reaches burn_after, the drop is queued in Redis and
+// the garbage collector deletes it through its reference-counting path. A
+// download that is never finished leaves the drop to expire 15 minutes later
+// (db.ExpiresAt).
+
+// markExhausted records that a drop has handed out its last download. It is
+// burned once burnAfter downloads have completed.
+func (s *Server) markExhausted(ctx context.Context, dropID string, burnAfter int) {
+	_, err := s.DB.ExecContext(ctx, `
+		UPDATE drops SET exhausted_at = NOW(), burn_after = $2
+		WHERE id = $1 AND exhausted_at IS NULL`,
+		dropID, burnAfter)
+	if err != nil {
+		log.Printf("[Burn Error] Failed to mark drop %s exhausted: %v", dropID, err)
+	}
+}
+
+// grantsExhausted reports whether every live grant of a drop has used up its
+// downloads, and how many downloads were started through all of its grants.
+func (s *Server) grantsExhausted(ctx context.Context, dropID string) (bool, int, error) {
+	rows, err := s.DB.QueryContext(ctx, "SELECT id, max_downloads, revoked_at IS NOT NULL FROM grants WHERE drop_id = $1", dropID)
+	if err != nil {
+		return false, 0, err
+	}
+	defer rows.Close()
+
+	exhausted, started := true, 0
+	for rows.Next() {
+		var id string
+		var maxDownloads int
+		var revoked bool
+		if err := rows.Scan(&id, &maxDownloads, &revoked); err != nil {
+			return false, 0, err
+		}
+		count, err := s.Cache.GrantDownloadCount(ctx, id)
+		if err != nil {
+			return false, 0, err
+		}
+		started += min(count, maxDownloads) // Refused attempts also increment the counter
+		if !revoked && count < maxDownloads {
+			exhausted = false
+		}
+	}
+	return exhausted, started, rows.Err()
+}
+
+// completeDownload counts the download behind token as served to the drop's
+// last chunk and queues the drop for burning once every started download has
+// completed. Fetching the last chunk again with the same token counts nothing.
+func (s *Server) completeDownload(ctx context.Context, dropID, token string, burnAfter int) {
+	first, err := s.Cache.CompleteDownloadToken(ctx, token)
+	if err != nil {
+		log.Printf("[Burn Error] Failed to mark download of drop %s complete: %v", dropID, err)
+		return
+	}
+	if !first {
+		return
+	}
+
+	completed, err := s.Cache.CompleteDownload(ctx, dropID)
+	if err != nil {
+		log.Printf("[Burn Error] Failed to count completed download of drop %s: %v", dropID, err)
+		return
+	}
+	if burnAfter > 0 && completed >= burnAfter {
+		if err := s.Cache.EnqueueBurn(ctx, dropID); err != nil {
+			log.Printf("[Burn Error] Failed to queue drop %s: %v", dropID, err)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumanthd032/codedrop/internal/crypto"
+	"github.com/sumanthd032/codedrop/internal/db"
+)
+
+// A short code can be claimed this many times before it is deleted. Every
+// claim is a guess the server cannot tell apart from a correct one, so this
+// caps an attacker at maxCodeClaims guesses out of 2^45 word combinations.
+const maxCodeClaims = 5
+
+// Per client IP limits on the short code endpoints
+const (
+	codeLookupLimit = 10 // lookups and claims per window
+	codeCreateLimit = 30 // new codes per window
+	codeLimitWindow = time.Minute
+)
+
+// handleCreateShortCode registers a short code for an existing drop and
+// allocates its nameplate. Only the drop's owner can, so nobody else can use
+// up the short nameplates.
+func (s *Server) handleCreateShortCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowCodeRequest(w, r, "create", codeCreateLimit) {
+			return
+		}
+
+		var req CreateShortCodeRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16*1024)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON payload", http.StatusBadRequest)
+			return
+		}
+		if !s.authorizeOwner(w, r, req.DropID) {
+			return
+		}
+		if _, err := crypto.ParseKDFParams(req.KDFParams); err != nil {
+			http.Error(w, "Invalid KDF parameters", http.StatusBadRequest)
+			return
+		}
+		if len(req.Verifier) != 32 || len(req.Fragment) == 0 || len(req.Fragment) > 4096 {
+			http.Error(w, "Invalid verifier or fragment", http.StatusBadRequest)
+			return
+		}
+
+		// 1. The drop must exist and still be live
+		var expiresAt time.Time
+		err := s.DB.QueryRow("SELECT "+db.ExpiresAt+" FROM drops WHERE id = $1", req.DropID).Scan(&expiresAt)
+		if err != nil {
+			http.Error(w, "Drop not found", http.StatusNotFound)
+			return
+		}
+		if time.Now().After(expiresAt) {
+			http.Error(w, "Drop has expired", http.StatusGone)
+			return
+		}
+
+		// 2. Pick a free nameplate, keeping them short while few codes are live
+		var nameplate int
+		for attempt := 0; nameplate == 0; attempt++ {
+			if attempt == 20 {
+				http.Error(w, "No free nameplate, try again", http.StatusServiceUnavailable)
+				return
+			}
+			candidate := 1 + rand.IntN(nameplateRange(attempt))
+			err := s.DB.QueryRow(`
+				INSERT INTO short_codes (nameplate, drop_id, kdf_params, verifier, fragment)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (nameplate) DO NOTHING
+				RETURNING nameplate`,
+				candidate, req.DropID, req.KDFParams, req.Verifier, req.Fragment).Scan(&nameplate)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				http.Error(w, "Database error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(CreateShortCodeResponse{Nameplate: nameplate})
+	}
+}
+
+// nameplateRange grows from 1-99 to 1-999999 as allocation attempts collide
+func nameplateRange(attempt int) int {
+	switch {
+	case attempt < 5:
+		return 99
+	case attempt < 10:
+		return 9999
+	default:
+		return 999999
+	}
+}
+
+// handleGetShortCode returns the KDF parameters pull needs before it can claim
+func (s *Server) handleGetShortCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowCodeRequest(w, r, "lookup", codeLookupLimit) {
+			return
+		}
+
+		var resp ShortCodeParamsResponse
+		err := s.DB.QueryRow(`
+			SELECT c.kdf_params FROM short_codes c
+			WHERE c.nameplate = $1 AND EXISTS (SELECT 1 FROM drops WHERE id = c.drop_id AND `+db.ExpiresAt+` > NOW())`,
+			chi.URLParam(r, "nameplate")).Scan(&resp.KDFParams)
+		if err != nil {
+			http.Error(w, "Short code not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// handleClaimShortCode runs the server side of SPAKE2. The answer is sealed
+// under the session key, so a wrong code learns nothing, not even the drop ID.
+func (s *Server) handleClaimShortCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowCodeRequest(w, r, "lookup", codeLookupLimit) {
+			return
+		}
+
+		nameplate, err := strconv.Atoi(chi.URLParam(r, "nameplate"))
+		if err != nil {
+			http.Error(w, "Short code not found", http.StatusNotFound)
+			return
+		}
+		var req ClaimShortCodeRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON payload", http.StatusBadRequest)
+			return
+		}
+
+		// 1. Count the claim before answering it
+		var claim ShortCodeClaim
+		var verifier []byte
+		var attempts int
+		err = s.DB.QueryRow(`
+			UPDATE short_codes c SET attempts = attempts + 1
+			WHERE c.nameplate = $1 AND EXISTS (SELECT 1 FROM drops WHERE id = c.drop_id AND `+db.ExpiresAt+` > NOW())
+			RETURNING c.drop_id, c.verifier, c.fragment, c.attempts`,
+			nameplate).Scan(&claim.DropID, &verifier, &claim.Fragment, &attempts)
+		if err != nil {
+			http.Error(w, "Short code not found", http.StatusNotFound)
+			return
+		}
+		if attempts >= maxCodeClaims {
+			if _, err := s.DB.Exec("DELETE FROM short_codes WHERE nameplate = $1", nameplate); err != nil {
+				log.Printf("Failed to delete used up short code %d: %v", nameplate, err)
+			}
+		}
+
+		// 2. Answer the SPAKE2 message and seal the claim under the session key
+		message, sessionKey, err := crypto.PAKEServerRespond(verifier, []byte(strconv.Itoa(nameplate)), req.Message)
+		if err != nil {
+			http.Error(w, "Invalid PAKE message", http.StatusBadRequest)
+			return
+		}
+		plaintext, err := json.Marshal(claim)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		sealed, err := crypto.SealShortCodeClaim(sessionKey, plaintext)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ClaimShortCodeResponse{Message: message, Claim: sealed})
+	}
+}
+
+// allowCodeRequest applies the per-IP rate limit, writing a 429 when it is exceeded.
+func (s *Server) allowCodeRequest(w http.ResponseWriter, r *http.Request, bucket string, limit int) bool {
+	return s.allowClientRequest(w, r, "code:"+bucket, limit, codeLimitWindow, "Too many short code requests, slow down")
+}
+
+// allowClientRequest counts a request against a per-IP fixed window for key,
+// writing a 429 with msg when the limit is exceeded.
+func (s *Server) allowClientRequest(w http.ResponseWriter, r *http.Request, key string, limit int, window time.Duration, msg string) bool {
+	allowed, err := s.Cache.AllowRequest(r.Context(), fmt.Sprintf("%s:%s", key, clientIP(r)), limit, window)
+	if err != nil {
+		http.Error(w, "Internal server error checking limits", http.StatusInternalServerError)
+		return false
+	}
+	if !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(window.Seconds())))
+		http.Error(w, msg, http.StatusTooManyRequests)
+		return false
+	}
+	return true
+}
+
+// clientIP is the address limits are counted against
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
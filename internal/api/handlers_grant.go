@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Access grants (push --recipients). A drop created with grants can only be
+// downloaded through one of them: the metadata and info endpoints take
+// ?grant=<id> and count downloads against that grant's own Redis counter. The
+// grant's wrapped drop key is handed out only with a counted download, or to
+// the owner. Revoking a grant deletes its wrapped key and refuses the chunks of
+// downloads already under way, so its link stops working even before it is used up.
+
+const maxGrants = 100
+
+var grantIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// validateGrants checks the grants sent with a new drop
+func validateGrants(grants []GrantRequest) error {
+	if len(grants) > maxGrants {
+		return fmt.Errorf("at most %d grants per drop", maxGrants)
+	}
+	labels := make(map[string]bool)
+	for _, g := range grants {
+		if !grantIDPattern.MatchString(g.ID) {
+			return fmt.Errorf("invalid grant ID")
+		}
+		if g.Label == "" || len(g.Label) > 64 || labels[g.Label] {
+			return fmt.Errorf("grant labels must be unique and 1-64 characters")
+		}
+		labels[g.Label] = true
+		if len(g.WrappedKey) == 0 || len(g.WrappedKey) > 256 {
+			return fmt.Errorf("invalid wrapped key for grant %q", g.Label)
+		}
+	}
+	return nil
+}
+
+// grant is an access grant as the download endpoints need it
+type grant struct {
+	WrappedKey   []byte
+	MaxDownloads int
+}
+
+// lookupGrant finds a live grant of the drop, writing the error response
+// itself when there is none or it was revoked.
+func (s *Server) lookupGrant(w http.ResponseWriter, dropID, grantID string) (*grant, bool) {
+	var g grant
+	var revoked bool
+	err := s.DB.QueryRow(`
+		SELECT wrapped_key, max_downloads, revoked_at IS NOT NULL
+		FROM grants WHERE id = $1 AND drop_id = $2`,
+		grantID, dropID).Scan(&g.WrappedKey, &g.MaxDownloads, &revoked)
+	if err != nil {
+		http.Error(w, "Grant not found", http.StatusNotFound)
+		return nil, false
+	}
+	if revoked {
+		http.Error(w, "This link has been revoked", http.StatusForbidden)
+		return nil, false
+	}
+	return &g, true
+}
+
+// handleGetGrantKey returns a grant's wrapped drop key to the drop's owner, so
+// a grant link can be reshared without spending one of its downloads.
+func (s *Server) handleGetGrantKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dropID := chi.URLParam(r, "id")
+		if !s.authorizeOwner(w, r, dropID) {
+			return
+		}
+
+		g, ok := s.lookupGrant(w, dropID, chi.URLParam(r, "grantID"))
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(GrantKeyResponse{WrappedKey: g.WrappedKey})
+	}
+}
+
+// handleRevokeGrant revokes one access grant. Its counter is left alone so
+// status still shows whether it was used.
+func (s *Server) handleRevokeGrant() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dropID := chi.URLParam(r, "id")
+		grantID := chi.URLParam(r, "grantID")
+		if !s.authorizeOwner(w, r, dropID) {
+			return
+		}
+
+		result, err := s.DB.Exec(`
+			UPDATE grants SET revoked_at = NOW(), wrapped_key = NULL
+			WHERE id = $1 AND drop_id = $2 AND revoked_at IS NULL`,
+			grantID, dropID)
+		if err != nil {
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			http.Error(w, "Grant not found or already revoked", http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumanthd032/codedrop/internal/db"
+)
+
+// Owner endpoints (codedrop reshare, status, revoke). handleCreateDrop returns
+// an owner token once; the server keeps only its SHA-256, and every owner
+// endpoint expects the token in the X-Owner-Token header.
+
+// newOwnerToken returns a random owner token and the hash stored in its place
+func newOwnerToken() (token, hash string, err error) {
+	token, err = randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	return token, hashOwnerToken(token), nil
+}
+
+// randomToken returns 32 random bytes, base64url encoded
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashOwnerToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ownerTokenMatches compares a presented token with the stored hash in
+// constant time. Drops created before owner tokens existed have none.
+func ownerTokenMatches(storedHash sql.NullString, token string) bool {
+	return storedHash.Valid && subtle.ConstantTimeCompare([]byte(storedHash.String), []byte(hashOwnerToken(token))) == 1
+}
+
+// authorizeOwner checks the request's owner token against the drop, writing
+// the error response itself when it does not match.
+func (s *Server) authorizeOwner(w http.ResponseWriter, r *http.Request, dropID string) bool {
+	ownerToken := r.Header.Get("X-Owner-Token")
+	if ownerToken == "" {
+		http.Error(w, "Missing X-Owner-Token header", http.StatusUnauthorized)
+		return false
+	}
+
+	var storedHash sql.NullString
+	err := s.DB.QueryRow("SELECT owner_token_hash FROM drops WHERE id = $1", dropID).Scan(&storedHash)
+	if err != nil {
+		http.Error(w, "Drop not found", http.StatusNotFound)
+		return false
+	}
+	if !ownerTokenMatches(storedHash, ownerToken) {
+		http.Error(w, "Invalid owner token", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// authorizeUpload checks the token presented with a chunk or manifest upload.
+// A normal drop is uploaded by its owner; a request slot by the sender who
+// filled it, with the upload token handleFillRequest issued.
+func (s *Server) authorizeUpload(w http.ResponseWriter, r *http.Request, dropID string) bool {
+	token := r.Header.Get("X-Owner-Token")
+	if token == "" {
+		http.Error(w, "Missing X-Owner-Token header", http.StatusUnauthorized)
+		return false
+	}
+
+	var ownerHash, uploadHash sql.NullString
+	var requested bool
+	err := s.DB.QueryRow("SELECT owner_token_hash, upload_token_hash, requested_for IS NOT NULL FROM drops WHERE id = $1", dropID).Scan(&ownerHash, &uploadHash, &requested)
+	if err != nil {
+		http.Error(w, "Drop not found", http.StatusNotFound)
+		return false
+	}
+	if requested {
+		ownerHash = uploadHash
+	}
+	if !ownerTokenMatches(ownerHash, token) {
+		http.Error(w, "Invalid owner token", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// handleDropStatus shows the owner a drop's downloads, per access grant for
+// drops pushed with --recipients. Unlike the info endpoint it answers for
+// drops that are used up or expired but not yet collected.
+func (s *Server) handleDropStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dropID := chi.URLParam(r, "id")
+		if !s.authorizeOwner(w, r, dropID) {
+			return
+		}
+
+		var resp DropStatusResponse
+		var notBefore sql.NullTime
+		err := s.DB.QueryRow("SELECT "+db.ExpiresAt+", max_downloads, not_before FROM drops WHERE id = $1", dropID).Scan(&resp.ExpiresAt, &resp.MaxDownloads, &notBefore)
+		if err != nil {
+			http.Error(w, "Drop not found", http.StatusNotFound)
+			return
+		}
+		resp.NotBefore = nullTime(notBefore)
+		resp.Downloads, err = s.Cache.DownloadCount(r.Context(), dropID)
+		if err != nil {
+			http.Error(w, "Internal server error checking limits", http.StatusInternalServerError)
+			return
+		}
+
+		rows, err := s.DB.Query(`
+			SELECT id, label, max_downloads, last_download_at, revoked_at
+			FROM grants WHERE drop_id = $1 ORDER BY label`, dropID)
+		if err != nil {
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var g GrantStatus
+			var lastDownload, revoked sql.NullTime
+			if err := rows.Scan(&g.ID, &g.Label, &g.MaxDownloads, &lastDownload, &revoked); err != nil {
+				http.Error(w, "Database error", http.StatusInternalServerError)
+				return
+			}
+			g.LastDownloadAt = nullTime(lastDownload)
+			g.RevokedAt = nullTime(revoked)
+			g.Downloads, err = s.Cache.GrantDownloadCount(r.Context(), g.ID)
+			if err != nil {
+				http.Error(w, "Internal server error checking limits", http.StatusInternalServerError)
+				return
+			}
+			resp.Grants = append(resp.Grants, g)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
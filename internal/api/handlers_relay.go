@@ -0,0 +1,210 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+)
+
+// Live relay (codedrop send / receive). The server pipes end-to-end encrypted
+// frames between two WebSocket clients. Nothing is written to the store or the
+// database, and a rendezvous lives only in memory until the receiver joins or
+// it expires.
+
+const (
+	relayDefaultWait = 10 * time.Minute
+	relayMaxWait     = time.Hour
+	relayMaxSessions = 100
+	relayMaxPerIP    = 4               // Open senders per client IP, waiting or transferring
+	relayOpenLimit   = 20              // New relays per client IP per minute
+	relayMaxMessage  = 2 * 1024 * 1024 // Chunks are 1 MiB plus framing
+	relayWriteWait   = 30 * time.Second
+)
+
+var relayUpgrader = websocket.Upgrader{
+	ReadBufferSize:  64 * 1024,
+	WriteBufferSize: 64 * 1024,
+}
+
+// relayHub tracks rendezvous waiting for their receiver, and how many senders
+// each client IP has open, so no single client can take every slot
+type relayHub struct {
+	mu       sync.Mutex
+	sessions map[string]*relaySession
+	perIP    map[string]int
+}
+
+type relaySession struct {
+	receiver chan *websocket.Conn // Handed over once, buffered
+	done     chan struct{}        // Closed when the transfer ends
+}
+
+func newRelayHub() *relayHub {
+	return &relayHub{sessions: make(map[string]*relaySession), perIP: make(map[string]int)}
+}
+
+// handleRelaySend opens a rendezvous for a sender and relays its frames once
+// the receiver connects
+func (s *Server) handleRelaySend() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowClientRequest(w, r, "relay", relayOpenLimit, time.Minute, "Too many relays opened, slow down") {
+			return
+		}
+
+		wait := relayDefaultWait
+		if v := r.URL.Query().Get("expires_in"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 || d > relayMaxWait {
+				http.Error(w, "Invalid expires_in (max 1h)", http.StatusBadRequest)
+				return
+			}
+			wait = d
+		}
+
+		// 1. Register the rendezvous
+		id, err := newRelayID()
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		session := &relaySession{receiver: make(chan *websocket.Conn, 1), done: make(chan struct{})}
+		ip := clientIP(r)
+		s.relays.mu.Lock()
+		if len(s.relays.sessions) >= relayMaxSessions {
+			s.relays.mu.Unlock()
+			http.Error(w, "Too many open relays, try again later", http.StatusServiceUnavailable)
+			return
+		}
+		if s.relays.perIP[ip] >= relayMaxPerIP {
+			s.relays.mu.Unlock()
+			http.Error(w, "Too many open relays from this address", http.StatusTooManyRequests)
+			return
+		}
+		s.relays.sessions[id] = session
+		s.relays.perIP[ip]++
+		s.relays.mu.Unlock()
+		defer close(session.done)
+		defer s.relays.release(id, ip)
+
+		sender, err := relayUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return // Upgrade already wrote the error
+		}
+		defer sender.Close()
+		sender.SetReadLimit(relayMaxMessage)
+
+		expiresAt := time.Now().Add(wait)
+		if err := sender.WriteJSON(RelayControl{Type: "ready", RelayID: id, ExpiresAt: expiresAt}); err != nil {
+			return
+		}
+
+		// 2. Wait for the receiver, or give up
+		var receiver *websocket.Conn
+		select {
+		case receiver = <-session.receiver:
+		case <-time.After(wait):
+			sender.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, "receiver did not connect in time"),
+				time.Now().Add(time.Second))
+			return
+		}
+		defer receiver.Close()
+		if err := sender.WriteJSON(RelayControl{Type: "connected"}); err != nil {
+			return
+		}
+
+		// 3. Pipe both ways until either side finishes
+		relayPipe(sender, receiver)
+	}
+}
+
+// handleRelayReceive joins a waiting rendezvous
+func (s *Server) handleRelayReceive() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Claim the rendezvous before upgrading, so a second receiver gets a plain 404
+		id := chi.URLParam(r, "id")
+		s.relays.mu.Lock()
+		session, ok := s.relays.sessions[id]
+		if ok {
+			delete(s.relays.sessions, id)
+		}
+		s.relays.mu.Unlock()
+		if !ok {
+			http.Error(w, "Relay not found or already joined", http.StatusNotFound)
+			return
+		}
+
+		receiver, err := relayUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		receiver.SetReadLimit(relayMaxMessage)
+
+		// The sender's handler owns the relay from here; it may also have given up meanwhile
+		select {
+		case session.receiver <- receiver:
+		case <-session.done:
+		}
+		<-session.done
+		receiver.Close()
+	}
+}
+
+// relayPipe copies messages in both directions. Each message is written before
+// the next is read, so a slow receiver slows the sender down through TCP
+// instead of filling server memory.
+func relayPipe(a, b *websocket.Conn) {
+	errc := make(chan error, 2)
+	copyMessages := func(dst, src *websocket.Conn) {
+		for {
+			kind, data, err := src.ReadMessage()
+			if err != nil {
+				errc <- err
+				return
+			}
+			dst.SetWriteDeadline(time.Now().Add(relayWriteWait))
+			if err := dst.WriteMessage(kind, data); err != nil {
+				errc <- err
+				return
+			}
+		}
+	}
+	go copyMessages(b, a)
+	go copyMessages(a, b)
+
+	// When one side closes, pass the close on so the other sees a clean end
+	err := <-errc
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	if ce, ok := err.(*websocket.CloseError); ok {
+		msg = websocket.FormatCloseMessage(ce.Code, ce.Text)
+	} else {
+		log.Printf("Relay ended: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	a.WriteControl(websocket.CloseMessage, msg, deadline)
+	b.WriteControl(websocket.CloseMessage, msg, deadline)
+}
+
+// release forgets a sender's rendezvous (if still waiting) and frees its slot
+func (h *relayHub) release(id, ip string) {
+	h.mu.Lock()
+	delete(h.sessions, id)
+	if h.perIP[ip]--; h.perIP[ip] <= 0 {
+		delete(h.perIP, ip)
+	}
+	h.mu.Unlock()
+}
+
+func newRelayID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumanthd032/codedrop/internal/db"
+)
+
+// Inbound requests (codedrop request / push --into). The requester creates an
+// empty drop through handleCreateDrop with only a public key. The sender looks
+// the key up here, fills in the slot once, then uploads chunks and the manifest
+// through the usual endpoints.
+
+// handleGetRequest returns the public key an open request slot is waiting for
+func (s *Server) handleGetRequest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dropID := chi.URLParam(r, "id")
+
+		var resp RequestSlotResponse
+		var filled bool
+		err := s.DB.QueryRow(`
+			SELECT requested_for, `+db.ExpiresAt+`, max_downloads, metadata IS NOT NULL
+			FROM drops WHERE id = $1 AND requested_for IS NOT NULL`,
+			dropID).Scan(&resp.Recipient, &resp.ExpiresAt, &resp.MaxDownloads, &filled)
+		if err != nil {
+			http.Error(w, "Request not found", http.StatusNotFound)
+			return
+		}
+
+		if time.Now().After(resp.ExpiresAt) {
+			http.Error(w, "Request has expired", http.StatusGone)
+			return
+		}
+		if filled {
+			http.Error(w, "Request has already been filled", http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// handleFillRequest claims an empty request slot for one sender. Only the first
+// fill succeeds; chunks and the manifest follow through the normal upload path,
+// authorized by the upload token returned here.
+func (s *Server) handleFillRequest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dropID := chi.URLParam(r, "id")
+
+		var req FillRequestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON payload", http.StatusBadRequest)
+			return
+		}
+		if len(req.Metadata) == 0 {
+			http.Error(w, "Missing metadata", http.StatusBadRequest)
+			return
+		}
+		if req.Algorithm == "" {
+			req.Algorithm = "v1-aes-gcm"
+		}
+		if len(req.Algorithm) > 64 || len(req.KeyID) > 64 {
+			http.Error(w, "Invalid algorithm or key identifier", http.StatusBadRequest)
+			return
+		}
+
+		uploadToken, uploadHash, err := newOwnerToken()
+		if err != nil {
+			http.Error(w, "Failed to generate upload token", http.StatusInternalServerError)
+			return
+		}
+
+		resp := CreateDropResponse{UploadToken: uploadToken}
+		err = s.DB.QueryRow(`
+			UPDATE drops
+			SET metadata = $1, file_size = $2, encryption_salt = $3, algorithm = $4, key_id = NULLIF($5, ''), wrapped_keys = $6, upload_token_hash = $8
+			WHERE id = $7 AND requested_for IS NOT NULL AND metadata IS NULL AND `+db.ExpiresAt+` > NOW()
+			RETURNING id, `+db.ExpiresAt,
+			req.Metadata, req.FileSize, req.EncryptionSalt, req.Algorithm, req.KeyID, req.WrappedKeys, dropID, uploadHash).Scan(&resp.DropID, &resp.ExpiresAt)
+		if err != nil {
+			http.Error(w, "Request not found, expired or already filled", http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
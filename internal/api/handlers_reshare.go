@@ -0,0 +1,112 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sumanthd032/codedrop/internal/db"
+)
+
+// Reshare (codedrop reshare). Chunks are content-addressed, so a fresh link
+// for an uploaded drop is a new drops row plus chunks rows pointing at the same
+// hashes; no bytes are uploaded again. The garbage collector only deletes an
+// object from storage once no chunks row references its hash.
+
+// handleReshareDrop creates a new drop sharing an existing drop's chunks, with
+// its own expiry, download limit and owner token. Needs the X-Owner-Token header.
+func (s *Server) handleReshareDrop() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dropID := chi.URLParam(r, "id")
+
+		ownerToken := r.Header.Get("X-Owner-Token")
+		if ownerToken == "" {
+			http.Error(w, "Missing X-Owner-Token header", http.StatusUnauthorized)
+			return
+		}
+
+		var req ReshareDropRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON payload", http.StatusBadRequest)
+			return
+		}
+		expiresAt, err := parseExpiry(req.ExpiresIn)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.MaxDownloads < 1 {
+			http.Error(w, "max_downloads must be at least 1", http.StatusBadRequest)
+			return
+		}
+
+		newToken, newHash, err := newOwnerToken()
+		if err != nil {
+			http.Error(w, "Failed to generate owner token", http.StatusInternalServerError)
+			return
+		}
+
+		// 1. Lock the original so the garbage collector cannot delete it (and
+		// reclaim its chunks) while the new drop's references are being added
+		tx, err := s.DB.Begin()
+		if err != nil {
+			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		var storedHash sql.NullString
+		var sealed bool
+		err = tx.QueryRow(`
+			SELECT owner_token_hash, manifest IS NOT NULL
+			FROM drops WHERE id = $1 AND `+db.ExpiresAt+` > NOW()
+			FOR UPDATE`,
+			dropID).Scan(&storedHash, &sealed)
+		if err != nil {
+			http.Error(w, "Drop not found or expired", http.StatusNotFound)
+			return
+		}
+		if !ownerTokenMatches(storedHash, ownerToken) {
+			http.Error(w, "Invalid owner token", http.StatusForbidden)
+			return
+		}
+		if !sealed {
+			http.Error(w, "Drop upload is not complete", http.StatusConflict)
+			return
+		}
+
+		// 2. Copy the drop. Metadata and manifest are sealed under the drop key,
+		// not the drop ID, so they carry over unchanged, and so do any embargo and
+		// idle and grace rules. Their clocks start again with the new drop.
+		resp := CreateDropResponse{ExpiresAt: expiresAt, OwnerToken: newToken}
+		err = tx.QueryRow(`
+			INSERT INTO drops (metadata, file_size, encryption_salt, algorithm, key_id, wrapped_keys, manifest, expires_at, max_downloads, owner_token_hash, not_before, idle_ttl_seconds, grace_seconds)
+			SELECT metadata, file_size, encryption_salt, algorithm, key_id, COALESCE($2, wrapped_keys), manifest, $3, $4, $5, not_before, idle_ttl_seconds, grace_seconds
+			FROM drops WHERE id = $1
+			RETURNING id`,
+			dropID, req.WrappedKeys, expiresAt, req.MaxDownloads, newHash).Scan(&resp.DropID)
+		if err != nil {
+			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// 3. Point the new drop at the same chunk hashes
+		_, err = tx.Exec(`
+			INSERT INTO chunks (drop_id, chunk_index, chunk_hash, size)
+			SELECT $1, chunk_index, chunk_hash, size FROM chunks WHERE drop_id = $2`,
+			resp.DropID, dropID)
+		if err != nil {
+			http.Error(w, "Metadata failure: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
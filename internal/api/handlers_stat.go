@@ -3,6 +3,8 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/sumanthd032/codedrop/internal/db"
 )
 
 // handleGetStats calculates and returns system metrics
@@ -11,7 +13,7 @@ func (s *Server) handleGetStats() http.HandlerFunc {
 		var resp StatsResponse
 
 		// 1. Count Active Drops (Not expired)
-		err := s.DB.QueryRow("SELECT COUNT(*) FROM drops WHERE expires_at > NOW()").Scan(&resp.ActiveDrops)
+		err := s.DB.QueryRow("SELECT COUNT(*) FROM drops WHERE " + db.ExpiresAt + " > NOW()").Scan(&resp.ActiveDrops)
 		if err != nil {
 			http.Error(w, "Database error counting drops", http.StatusInternalServerError)
 			return
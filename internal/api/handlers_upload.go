@@ -10,6 +10,7 @@ import (
 	"io"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/sumanthd032/codedrop/internal/crypto"
 )
 
 // handleCreateDrop initiates the upload session
@@ -23,46 +24,147 @@ func (s *Server) handleCreateDrop() http.HandlerFunc {
 		}
 
 		// 1. Calculate Expiry
-		duration, err := time.ParseDuration(req.ExpiresIn)
+		expiresAt, err := parseExpiry(req.ExpiresIn)
 		if err != nil {
-			http.Error(w, "Invalid duration format (use 1h, 30m)", http.StatusBadRequest)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		if duration > 24*time.Hour {    // We set the max limit to 24 hours for security reasons
-			http.Error(w, "Max expiry is 24 hours", http.StatusBadRequest)
+		if req.MaxDownloads < 1 {
+			http.Error(w, "max_downloads must be at least 1", http.StatusBadRequest)
 			return
 		}
-		expiresAt := time.Now().Add(duration)
 
-		// 2. Insert into Database
+		// The algorithm is opaque to the server; it is only stored for the receiving CLI
+		if req.Algorithm == "" {
+			req.Algorithm = "v1-aes-gcm"
+		}
+		if len(req.Algorithm) > 64 || len(req.KeyID) > 64 {
+			http.Error(w, "Invalid algorithm or key identifier", http.StatusBadRequest)
+			return
+		}
+
+		// A request slot starts empty; the sender fills it later with push --into
+		if req.Recipient != "" {
+			if _, err := crypto.ParseRecipient(req.Recipient); err != nil || len(req.Metadata) > 0 {
+				http.Error(w, "Invalid request recipient", http.StatusBadRequest)
+				return
+			}
+			req.Metadata = nil
+		} else if len(req.Metadata) == 0 {
+			http.Error(w, "Missing metadata", http.StatusBadRequest)
+			return
+		}
+		if len(req.Grants) > 0 && (req.Recipient != "" || validateGrants(req.Grants) != nil) {
+			http.Error(w, "Invalid access grants", http.StatusBadRequest)
+			return
+		}
+		// Optional lifecycle rules that can expire the drop before expires_at
+		idleTTL, err := parseLifetime("idle_ttl", req.IdleTTL)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		grace, err := parseLifetime("grace_period", req.GracePeriod)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// An embargo that outlasts the drop would make it undownloadable
+		if req.NotBefore != nil && !req.NotBefore.Before(expiresAt) {
+			http.Error(w, "not_before must be before the drop expires", http.StatusBadRequest)
+			return
+		}
+
+		// The owner token lets the uploader manage the drop later (reshare); only its hash is kept
+		ownerToken, ownerHash, err := newOwnerToken()
+		if err != nil {
+			http.Error(w, "Failed to generate owner token", http.StatusInternalServerError)
+			return
+		}
+
+		// 2. Insert into Database, together with any access grants
+		tx, err := s.DB.Begin()
+		if err != nil {
+			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
 		var dropID string
 		query := `
-			INSERT INTO drops (file_name, file_size, encryption_salt, expires_at, max_downloads)
-			VALUES ($1, $2, $3, $4, $5)
+			INSERT INTO drops (metadata, file_size, encryption_salt, algorithm, key_id, wrapped_keys, expires_at, max_downloads, requested_for, owner_token_hash, grants_only, not_before, idle_ttl_seconds, grace_seconds)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, NULLIF($9, ''), $10, $11, $12, $13, $14)
 			RETURNING id`
 		
-		err = s.DB.QueryRow(query, req.FileName, req.FileSize, req.EncryptionSalt, expiresAt, req.MaxDownloads).Scan(&dropID)
+		err = tx.QueryRow(query, req.Metadata, req.FileSize, req.EncryptionSalt, req.Algorithm, req.KeyID, req.WrappedKeys, expiresAt, req.MaxDownloads, req.Recipient, ownerHash, len(req.Grants) > 0, req.NotBefore, idleTTL, grace).Scan(&dropID)
 		if err != nil {
 			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		// Each grant gets the drop's download limit on its own counter
+		for _, g := range req.Grants {
+			_, err = tx.Exec(`
+				INSERT INTO grants (id, drop_id, label, wrapped_key, max_downloads)
+				VALUES ($1, $2, $3, $4, $5)`,
+				g.ID, dropID, g.Label, g.WrappedKey, req.MaxDownloads)
+			if err != nil {
+				http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
 		// 3. Return the Drop ID
 		resp := CreateDropResponse{
-			DropID:    dropID,
-			ExpiresAt: expiresAt,
+			DropID:     dropID,
+			ExpiresAt:  expiresAt,
+			OwnerToken: ownerToken,
 		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(resp)
 	}
 }
 
+// parseExpiry turns an expires_in value such as "1h" into an absolute time
+func parseExpiry(expiresIn string) (time.Time, error) {
+	duration, err := time.ParseDuration(expiresIn)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid duration format (use 1h, 30m)")
+	}
+	if duration > 24*time.Hour { // We set the max limit to 24 hours for security reasons
+		return time.Time{}, fmt.Errorf("Max expiry is 24 hours")
+	}
+	return time.Now().Add(duration), nil
+}
 
+// parseLifetime turns an idle_ttl or grace_period value into seconds. An empty
+// value means the rule is not used and is stored as NULL.
+func parseLifetime(name, value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < time.Minute || duration > 24*time.Hour {
+		return nil, fmt.Errorf("Invalid %s (use 1m to 24h, e.g. 30m)", name)
+	}
+	seconds := int(duration / time.Second)
+	return &seconds, nil
+}
 
-// handleUploadChunk receives a binary piece of the file
+// handleUploadChunk receives a binary piece of the file. Like the manifest,
+// it needs the uploader's X-Owner-Token.
 func (s *Server) handleUploadChunk() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		dropID := chi.URLParam(r, "id")
+		if !s.authorizeUpload(w, r, dropID) {
+			return
+		}
 		
 		chunkIndex := r.Header.Get("X-Chunk-Index")
 		if chunkIndex == "" {
@@ -111,4 +213,42 @@ func (s *Server) handleUploadChunk() http.HandlerFunc {
 			"hash":   chunkHash,
 		})
 	}
-}
\ No newline at end of file
+}
+
+// handleUploadManifest stores the encrypted manifest once all chunks are uploaded.
+// The manifest is write-once: a drop that already has one cannot be re-sealed,
+// and only the uploader (X-Owner-Token) can seal it.
+func (s *Server) handleUploadManifest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dropID := chi.URLParam(r, "id")
+		if !s.authorizeUpload(w, r, dropID) {
+			return
+		}
+
+		const MaxManifestSize = 1 * 1024 * 1024
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxManifestSize))
+		if err != nil {
+			http.Error(w, "Manifest too large or read error", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if len(data) == 0 {
+			http.Error(w, "Empty manifest", http.StatusBadRequest)
+			return
+		}
+
+		result, err := s.DB.Exec(`
+			UPDATE drops SET manifest = $1
+			WHERE id = $2 AND manifest IS NULL AND metadata IS NOT NULL`,
+			data, dropID)
+		if err != nil {
+			http.Error(w, "Metadata failure: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			http.Error(w, "Drop not found or manifest already uploaded", http.StatusConflict)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import "testing"
+
+func TestParseLifetime(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int // Seconds; 0 when no rule is set
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"30m", 1800, false},
+		{"1m", 60, false},
+		{"24h", 86400, false},
+		{"90s", 90, false},
+		{"59s", 0, true},
+		{"25h", 0, true},
+		{"-10m", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseLifetime("idle_ttl", tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected %q to be rejected", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tt.want == 0 {
+				if got != nil {
+					t.Errorf("Expected no rule, got %d seconds", *got)
+				}
+				return
+			}
+			if got == nil || *got != tt.want {
+				t.Errorf("Expected %d seconds, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// The download page for recipients without the CLI. It decrypts in the
+// browser: the key stays in the URL fragment, which browsers never send.
+//
+//go:embed web
+var webFiles embed.FS
+
+// webCSP only lets the page talk to this server and run its own script, so
+// nothing injected into a drop's metadata can exfiltrate the key.
+const webCSP = "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; img-src 'self'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+// handleDropPage serves the page at /drop/{id}, the URL that push prints.
+func (s *Server) handleDropPage() http.HandlerFunc {
+	page, err := webFiles.ReadFile("web/drop.html")
+	if err != nil {
+		panic(err) // Embedded at build time; cannot be missing
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		setWebHeaders(w)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	}
+}
+
+// handleStatic serves the page's script and stylesheet.
+func (s *Server) handleStatic() http.Handler {
+	sub, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err)
+	}
+	files := http.StripPrefix("/static/", http.FileServer(http.FS(sub)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setWebHeaders(w)
+		files.ServeHTTP(w, r)
+	})
+}
+
+func setWebHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Security-Policy", webCSP)
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-store")
+}
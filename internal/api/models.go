@@ -4,17 +4,43 @@ import "time"
 
 // CreateDropRequest is what the CLI sends to start an upload
 type CreateDropRequest struct {
-	FileName       string `json:"file_name"`
-	FileSize       int64  `json:"file_size"`
-	EncryptionSalt string `json:"encryption_salt"` // The salt used for client-side encryption
-	ExpiresIn      string `json:"expires_in"`      // e.g., "1h", "30m"
-	MaxDownloads   int    `json:"max_downloads"`
+	Metadata       []byte         `json:"metadata"` // File name, MIME type etc., encrypted under the drop key
+	FileSize       int64          `json:"file_size"`
+	EncryptionSalt string         `json:"encryption_salt"`        // The salt used for client-side encryption
+	Algorithm      string         `json:"algorithm"`              // e.g., "v1-aes-gcm", "v1-aes-gcm-private"
+	KeyID          string         `json:"key_id,omitempty"`       // Team secret identifier for team-keyed drops
+	WrappedKeys    []byte         `json:"wrapped_keys,omitempty"` // Drop key wrapped to recipients, opaque JSON
+	ExpiresIn      string         `json:"expires_in"`             // e.g., "1h", "30m"
+	MaxDownloads   int            `json:"max_downloads"`
+	Recipient      string         `json:"recipient,omitempty"`    // Set by codedrop request: creates an empty slot for this public key
+	Grants         []GrantRequest `json:"grants,omitempty"`       // push --recipients: one link per person; the drop is only downloadable through these
+	NotBefore      *time.Time     `json:"not_before,omitempty"`   // push --not-before: nothing can be downloaded before this time
+	IdleTTL        string         `json:"idle_ttl,omitempty"`     // push --idle-ttl: expire after this long without a download, e.g. "30m"
+	GracePeriod    string         `json:"grace_period,omitempty"` // push --grace: expire this long after the first download
+}
+
+// GrantRequest is one access grant created with a drop. The ID is derived from
+// a grant key that only the grant's URL carries.
+type GrantRequest struct {
+	ID         string `json:"id"`
+	Label      string `json:"label"`       // Who the link is for, e.g. "alice"
+	WrappedKey []byte `json:"wrapped_key"` // Drop key wrapped under the grant key
 }
 
 // CreateDropResponse is what the server sends back
 type CreateDropResponse struct {
-	DropID    string    `json:"drop_id"`
-	ExpiresAt time.Time `json:"expires_at"`
+	DropID      string    `json:"drop_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	OwnerToken  string    `json:"owner_token,omitempty"`  // Shown once; authorizes the upload and reshare
+	UploadToken string    `json:"upload_token,omitempty"` // Filling a request slot: authorizes the upload only
+}
+
+// ReshareDropRequest asks for a new link to an uploaded drop. The new drop
+// points at the same chunks; WrappedKeys, when set, replaces the recipients.
+type ReshareDropRequest struct {
+	ExpiresIn    string `json:"expires_in"`
+	MaxDownloads int    `json:"max_downloads"`
+	WrappedKeys  []byte `json:"wrapped_keys,omitempty"`
 }
 
 // ChunkUploadResponse confirms a chunk was saved
@@ -27,13 +53,123 @@ type ChunkUploadResponse struct {
 // 1. CLI sends CreateDropRequest to /api/v1/drops (POST)
 // 2. Server responds with CreateDropResponse (drop ID and expiration time)
 // 3. CLI uploads file chunks to /api/v1/drops/{drop_id}/chunks (POST) with ChunkUploadResponse confirming each chunk
+// 4. CLI uploads the encrypted manifest to /api/v1/drop/{drop_id}/manifest (PUT), sealing the drop
 
-// GetDropMetadataResponse is what the server sends back when the CLI requests metadata about a drop
-type GetDropMetadataResponse struct {
-	FileName       string `json:"file_name"`
+// RequestSlotResponse tells push --into who the requester is
+type RequestSlotResponse struct {
+	Recipient    string    `json:"recipient"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	MaxDownloads int       `json:"max_downloads"`
+}
+
+// FillRequestRequest is what push --into sends to claim an empty slot. Expiry
+// and download limit were already chosen by the requester.
+type FillRequestRequest struct {
+	Metadata       []byte `json:"metadata"`
 	FileSize       int64  `json:"file_size"`
 	EncryptionSalt string `json:"encryption_salt"`
-	ChunkCount     int    `json:"chunk_count"`
+	Algorithm      string `json:"algorithm"`
+	KeyID          string `json:"key_id,omitempty"`
+	WrappedKeys    []byte `json:"wrapped_keys,omitempty"`
+}
+
+// GetDropMetadataResponse is what the server sends back when the CLI requests metadata about a drop
+type GetDropMetadataResponse struct {
+	Metadata        []byte `json:"metadata"`
+	FileSize        int64  `json:"file_size"`
+	EncryptionSalt  string `json:"encryption_salt"`
+	Algorithm       string `json:"algorithm"`
+	KeyID           string `json:"key_id,omitempty"`
+	WrappedKeys     []byte `json:"wrapped_keys,omitempty"`
+	ChunkCount      int    `json:"chunk_count"`
+	Manifest        []byte `json:"manifest"`                    // Encrypted manifest, base64 encoded in JSON
+	DownloadToken   string `json:"download_token"`              // Sent with every chunk request of this download
+	GrantWrappedKey []byte `json:"grant_wrapped_key,omitempty"` // With ?grant=: the drop key wrapped under the grant key
+}
+
+// DropInfoResponse describes a drop without consuming one of its downloads
+type DropInfoResponse struct {
+	Metadata       []byte     `json:"metadata"`
+	EncryptionSalt string     `json:"encryption_salt"`
+	Algorithm      string     `json:"algorithm"`
+	KeyID          string     `json:"key_id,omitempty"`
+	WrappedKeys    []byte     `json:"wrapped_keys,omitempty"`
+	FileSize       int64      `json:"file_size"`
+	ChunkCount     int        `json:"chunk_count"`
+	Manifest       []byte     `json:"manifest"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	MaxDownloads   int        `json:"max_downloads"`
+	Downloads      int        `json:"downloads"`
+	NotBefore      *time.Time `json:"not_before,omitempty"` // Embargoed drops can't be downloaded before this time
+}
+
+// DropStatusResponse is what the owner sees with codedrop status
+type DropStatusResponse struct {
+	ExpiresAt    time.Time     `json:"expires_at"`
+	MaxDownloads int           `json:"max_downloads"`
+	Downloads    int           `json:"downloads"`
+	Grants       []GrantStatus `json:"grants,omitempty"`
+	NotBefore    *time.Time    `json:"not_before,omitempty"`
+}
+
+// GrantStatus reports one access grant's downloads and whether it was revoked
+type GrantStatus struct {
+	ID             string     `json:"id"`
+	Label          string     `json:"label"`
+	MaxDownloads   int        `json:"max_downloads"`
+	Downloads      int        `json:"downloads"`
+	LastDownloadAt *time.Time `json:"last_download_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
+
+// GrantKeyResponse hands the owner a grant's wrapped drop key (reshare of a grant link)
+type GrantKeyResponse struct {
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+// CreateShortCodeRequest registers a short code for a drop (push --code)
+type CreateShortCodeRequest struct {
+	DropID    string `json:"drop_id"`
+	KDFParams string `json:"kdf_params"` // Argon2id parameters and salt, as in encryption_salt
+	Verifier  []byte `json:"verifier"`   // SPAKE2 password scalar derived from the code's words
+	Fragment  []byte `json:"fragment"`   // URL fragment sealed under a key the server never sees
+}
+
+// CreateShortCodeResponse returns the nameplate, the number at the front of the code
+type CreateShortCodeResponse struct {
+	Nameplate int `json:"nameplate"`
+}
+
+// ShortCodeParamsResponse tells pull how to stretch the code's words
+type ShortCodeParamsResponse struct {
+	KDFParams string `json:"kdf_params"`
+}
+
+// ClaimShortCodeRequest carries the client's SPAKE2 message
+type ClaimShortCodeRequest struct {
+	Message []byte `json:"message"`
+}
+
+// ClaimShortCodeResponse carries the server's SPAKE2 message and, sealed under
+// the session key, a ShortCodeClaim. Only the right code can open it.
+type ClaimShortCodeResponse struct {
+	Message []byte `json:"message"`
+	Claim   []byte `json:"claim"`
+}
+
+// ShortCodeClaim is the plaintext inside ClaimShortCodeResponse.Claim
+type ShortCodeClaim struct {
+	DropID   string `json:"drop_id"`
+	Fragment []byte `json:"fragment"`
+}
+
+// RelayControl is a JSON message the server sends a relay sender: "ready" with
+// the rendezvous ID once it is open, then "connected" when the receiver joins.
+// Everything after that is end-to-end between the clients.
+type RelayControl struct {
+	Type      string    `json:"type"`
+	RelayID   string    `json:"relay_id,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
 }
 
 // StatsResponse represents the current health and storage metrics of the system
@@ -42,4 +178,4 @@ type StatsResponse struct {
 	TotalChunks  int   `json:"total_chunks"`
 	StorageUsed  int64 `json:"storage_used_bytes"`
 	StorageSaved int64 `json:"storage_saved_bytes"`
-}
\ No newline at end of file
+}
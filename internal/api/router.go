@@ -17,6 +17,8 @@ type Server struct {
 	Store *store.Store
 	Cache *cache.RedisClient
 	Router *chi.Mux
+
+	relays *relayHub
 }
 
 // NewServer initializes the router and dependencies
@@ -26,6 +28,7 @@ func NewServer(db *db.DB, store *store.Store, cacheClient *cache.RedisClient) *S
 		Store: store,
 		Cache: cacheClient,
 		Router: chi.NewRouter(),
+		relays: newRelayHub(),
 	}
 
 	s.routes()
@@ -39,28 +42,57 @@ func (s *Server) routes() {
 	s.Router.Use(middleware.Logger)
 	// Recoverer: If code panics (crashes), this catches it and returns 500 instead of killing the server
 	s.Router.Use(middleware.Recoverer)
-	// Timeout: Hard limit of 60s per request to prevent hanging connections
-	s.Router.Use(middleware.Timeout(60 * time.Second))
+	// Timeout: Hard limit of 60s per request to prevent hanging connections.
+	// Applied per group, because relay WebSockets stay open for a whole transfer.
+	timeout := middleware.Timeout(60 * time.Second)
 
 	// Routes
-	s.Router.Get("/health", s.handleHealthCheck())
+	s.Router.With(timeout).Get("/health", s.handleHealthCheck())
+
+	// Browser download page for the URLs push prints (decrypts client-side)
+	s.Router.With(timeout).Get("/drop/{id}", s.handleDropPage())
+	s.Router.With(timeout).Handle("/static/*", s.handleStatic())
 	
 	// API Group (v1)
 	s.Router.Route("/api/v1", func(r chi.Router) {
-		// API endpoints will go here 
-		r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
-			w.Write([]byte("pong"))
-		})
+		// Live relay (WebSocket, no timeout; the server stores nothing)
+		r.Get("/relay", s.handleRelaySend())
+		r.Get("/relay/{id}", s.handleRelayReceive())
+
+		r.Group(func(r chi.Router) {
+			r.Use(timeout)
+
+			r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("pong"))
+			})
 
-		// Upload Endpoints
-		r.Post("/drop", s.handleCreateDrop())
-		r.Post("/drop/{id}/chunk", s.handleUploadChunk())
+			// Upload Endpoints
+			r.Post("/drop", s.handleCreateDrop())
+			r.Post("/drop/{id}/chunk", s.handleUploadChunk())
+			r.Put("/drop/{id}/manifest", s.handleUploadManifest())
 
-		// Download Endpoints
-		r.Get("/drop/{id}", s.handleGetDropMetadata())
-		r.Get("/drop/{id}/chunk/{chunkIndex}", s.handleDownloadChunk())
+			// Download Endpoints
+			r.Get("/drop/{id}", s.handleGetDropMetadata())
+			r.Get("/drop/{id}/info", s.handleGetDropInfo())
+			r.Get("/drop/{id}/chunk/{chunkIndex}", s.handleDownloadChunk())
 
-		// Stats Endpoint
-		r.Get("/stats", s.handleGetStats())
+			// Owner Endpoints (X-Owner-Token from drop creation)
+			r.Post("/drop/{id}/reshare", s.handleReshareDrop())
+			r.Get("/drop/{id}/status", s.handleDropStatus())
+			r.Get("/drop/{id}/grant/{grantID}", s.handleGetGrantKey())
+			r.Delete("/drop/{id}/grant/{grantID}", s.handleRevokeGrant())
+
+			// Inbound Request Endpoints (codedrop request / push --into)
+			r.Get("/request/{id}", s.handleGetRequest())
+			r.Put("/request/{id}", s.handleFillRequest())
+
+			// Short Code Endpoints (rate limited per client)
+			r.Post("/code", s.handleCreateShortCode())
+			r.Get("/code/{nameplate}", s.handleGetShortCode())
+			r.Post("/code/{nameplate}/claim", s.handleClaimShortCode())
+
+			// Stats Endpoint
+			r.Get("/stats", s.handleGetStats())
+		})
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,308 @@
+// CodeDrop browser client. Mirrors `codedrop pull` for plain #k= links and
+// #g= grant links: the key is read from the URL fragment, which the browser
+// never sends, and every chunk is verified against the encrypted manifest
+// before it is decrypted.
+"use strict";
+
+(function () {
+  const enc = new TextEncoder();
+  const metadataAD = enc.encode("codedrop-metadata-v1");
+  const manifestAD = enc.encode("codedrop-manifest-v1");
+  const commitmentContext = enc.encode("codedrop-key-commitment-v2");
+  const grantIDLabel = enc.encode("codedrop-grant-id-v1");
+  const commitmentSize = 32;
+  const nonceSize = 12; // AES-GCM
+  const bundleMimeType = "application/x-codedrop-bundle";
+  const bundleMessage = "This drop is a bundle of several files. Use the codedrop CLI: codedrop pull \"<url>\"";
+  // The page holds the whole decrypted file in memory before saving it, so
+  // larger drops are left to the CLI, which streams them to disk
+  const maxBrowserSize = 512 * 1024 * 1024;
+  const tooLargeMessage = "This drop is larger than " + formatBytes(maxBrowserSize) + ", too large to decrypt in the browser. Use the codedrop CLI: codedrop pull \"<url>\"";
+
+  const $ = (id) => document.getElementById(id);
+
+  function setStatus(text, isError) {
+    $("status").textContent = text;
+    $("status").className = isError ? "error" : "";
+  }
+
+  function fail(text) {
+    setStatus(text, true);
+    $("download").disabled = true;
+    $("progress").hidden = true;
+  }
+
+  // Go encodes []byte as standard base64 in JSON and keys as URL-safe base64.
+  function fromBase64(s) {
+    const bin = atob(s.replace(/-/g, "+").replace(/_/g, "/"));
+    const out = new Uint8Array(bin.length);
+    for (let i = 0; i < bin.length; i++) out[i] = bin.charCodeAt(i);
+    return out;
+  }
+
+  function toHex(buf) {
+    return Array.from(new Uint8Array(buf), (b) => b.toString(16).padStart(2, "0")).join("");
+  }
+
+  function concat(...parts) {
+    const out = new Uint8Array(parts.reduce((n, p) => n + p.length, 0));
+    let off = 0;
+    for (const p of parts) {
+      out.set(p, off);
+      off += p.length;
+    }
+    return out;
+  }
+
+  function formatBytes(n) {
+    const units = ["B", "KB", "MB", "GB", "TB"];
+    let i = 0;
+    while (n >= 1024 && i < units.length - 1) {
+      n /= 1024;
+      i++;
+    }
+    return (i === 0 ? n : n.toFixed(2)) + " " + units[i];
+  }
+
+  // safeFileName matches the CLI: only the base name, never a path.
+  function safeFileName(name, dropID) {
+    const base = (name || "").split(/[\\/]/).pop().replace(/[\x00-\x1f]/g, "");
+    return base && base !== "." && base !== ".." ? base : dropID;
+  }
+
+  // parseAlgorithm mirrors the suite registry. Only AES-GCM is available in
+  // WebCrypto; XChaCha20-Poly1305 drops need the CLI.
+  function parseAlgorithm(alg) {
+    const m = /^v([12])-(aes-gcm|xchacha20-poly1305)(?:-(private|team))?$/.exec(alg || "v1-aes-gcm");
+    if (!m) throw new Error("Unsupported encryption algorithm " + JSON.stringify(alg) + ". Use the codedrop CLI.");
+    if (m[2] !== "aes-gcm") throw new Error("This drop uses " + m[2] + ", which browsers cannot decrypt. Use the codedrop CLI.");
+    return { id: alg || "v1-aes-gcm", version: Number(m[1]), keyMode: m[3] || "convergent" };
+  }
+
+  // grantID mirrors crypto.GrantID: the first 16 bytes of SHA-256(label || grant key), in hex.
+  async function grantID(grantKey) {
+    const digest = await crypto.subtle.digest("SHA-256", concat(grantIDLabel, grantKey));
+    return toHex(digest.slice(0, 16));
+  }
+
+  // unwrapGrantKey mirrors crypto.UnwrapGrantKey: nonce || AES-GCM(drop key), bound to the grant ID.
+  async function unwrapGrantKey(grantKey, id, wrapped) {
+    if (wrapped.length < nonceSize) throw new Error("wrapped key too short");
+    const aes = await crypto.subtle.importKey("raw", grantKey, "AES-GCM", false, ["decrypt"]);
+    const params = { name: "AES-GCM", iv: wrapped.subarray(0, nonceSize), additionalData: enc.encode("codedrop-grant-wrap-v1|" + id) };
+    return new Uint8Array(await crypto.subtle.decrypt(params, aes, wrapped.subarray(nonceSize)));
+  }
+
+  async function importKeys(raw) {
+    return {
+      raw: raw,
+      aes: await crypto.subtle.importKey("raw", raw, "AES-GCM", false, ["decrypt"]),
+      hmac: await crypto.subtle.importKey("raw", raw, { name: "HMAC", hash: "SHA-256" }, false, ["sign"]),
+    };
+  }
+
+  // open reverses Suite.seal / Suite.Encrypt: [commitment (v2)] || nonce || ciphertext || tag.
+  async function open(suite, keys, sealed, ad) {
+    if (suite.version >= 2) {
+      if (sealed.length < commitmentSize + nonceSize) throw new Error("ciphertext too short");
+      const commitment = sealed.subarray(0, commitmentSize);
+      sealed = sealed.subarray(commitmentSize);
+      const expected = new Uint8Array(await crypto.subtle.sign("HMAC", keys.hmac, concat(commitmentContext, sealed.subarray(0, nonceSize))));
+      let diff = 0;
+      for (let i = 0; i < commitmentSize; i++) diff |= commitment[i] ^ expected[i];
+      if (diff !== 0) throw new Error("key commitment mismatch");
+    }
+    if (sealed.length < nonceSize) throw new Error("ciphertext too short");
+    const params = { name: "AES-GCM", iv: sealed.subarray(0, nonceSize) };
+    if (ad) params.additionalData = ad;
+    return new Uint8Array(await crypto.subtle.decrypt(params, keys.aes, sealed.subarray(nonceSize)));
+  }
+
+  async function openJSON(suite, keys, b64, ad) {
+    const plaintext = await open(suite, keys, fromBase64(b64), ad);
+    return JSON.parse(new TextDecoder().decode(plaintext));
+  }
+
+  async function fetchOK(url, headers) {
+    const resp = await fetch(url, { cache: "no-store", referrerPolicy: "no-referrer", headers: headers || {} });
+    if (!resp.ok) {
+      const body = (await resp.text()).trim();
+      if (resp.status === 425) throw new Error("This drop is not available until " + new Date(resp.headers.get("X-Not-Before")).toLocaleString() + ".");
+      throw new Error(resp.status === 410 ? "This drop has expired or reached its download limit." : body || resp.statusText);
+    }
+    return resp;
+  }
+
+  async function main() {
+    const match = /^\/drop\/([^/]+)$/.exec(location.pathname);
+    if (!match) return fail("Invalid drop URL.");
+    const dropID = decodeURIComponent(match[1]);
+    const api = "/api/v1/drop/" + encodeURIComponent(dropID);
+
+    const fragment = new URLSearchParams(location.hash.slice(1));
+    if (!fragment.has("k") && !fragment.has("g")) {
+      if (fragment.has("w")) return fail("This drop is passphrase-protected. Use the codedrop CLI: codedrop pull \"<url>\"");
+      if (fragment.has("s")) return fail("This drop is split into key shares. Use the codedrop CLI with all the share URLs.");
+      return fail("This link has no key. It is either incomplete or encrypted to recipients; use the codedrop CLI.");
+    }
+
+    // A grant link (push --recipients) reads, and is counted, through its own
+    // grant. Its drop key, wrapped under the grant key, only comes with the
+    // counted download, so the file's details stay hidden until then.
+    let query = "";
+    let grant = null;
+    if (fragment.has("g")) {
+      try {
+        const grantKey = fromBase64(fragment.get("g"));
+        if (grantKey.length !== 32) throw new Error("expected 32 bytes, got " + grantKey.length);
+        grant = { key: grantKey, id: await grantID(grantKey) };
+      } catch (err) {
+        return fail("Invalid grant key: " + err.message);
+      }
+      query = "?grant=" + grant.id;
+    }
+
+    // The info endpoint is read-only, so showing details does not cost a view
+    const info = await (await fetchOK(api + "/info" + query)).json();
+
+    const suite = parseAlgorithm(info.algorithm);
+    let keys = null;
+    let meta = null;
+    if (grant) {
+      $("name").textContent = "(shown once the download starts)";
+      $("type").textContent = "unknown";
+      $("size").textContent = formatBytes(info.file_size);
+    } else {
+      try {
+        keys = await loadKeys(fromBase64(fragment.get("k")));
+      } catch (err) {
+        return fail("Invalid key: " + err.message);
+      }
+      try {
+        meta = await openMetadata(suite, keys, info.metadata);
+      } catch (err) {
+        return fail(err.message);
+      }
+      $("name").textContent = safeFileName(meta.name, dropID);
+      $("type").textContent = meta.mime_type || "unknown";
+      $("size").textContent = formatBytes(meta.size);
+    }
+    $("expires").textContent = new Date(info.expires_at).toLocaleString();
+    $("views").textContent = info.max_downloads - info.downloads + " of " + info.max_downloads;
+    $("details").hidden = false;
+    setStatus("");
+    if (info.not_before && new Date(info.not_before) > new Date()) {
+      return fail("This drop is not available until " + new Date(info.not_before).toLocaleString() + ". Reload the page then.");
+    }
+    if (meta && meta.mime_type === bundleMimeType) {
+      return fail(bundleMessage);
+    }
+    // The stored size includes any padding, so it is at least the file's size
+    if (info.file_size > maxBrowserSize) {
+      return fail(tooLargeMessage);
+    }
+
+    $("download").addEventListener("click", () => {
+      $("download").disabled = true;
+      download(api, query, dropID, suite, keys, meta, grant).catch((err) => fail(err.message));
+    });
+  }
+
+  async function loadKeys(raw) {
+    if (raw.length !== 32) throw new Error("expected 32 bytes, got " + raw.length);
+    return importKeys(raw);
+  }
+
+  async function openMetadata(suite, keys, b64) {
+    try {
+      return await openJSON(suite, keys, b64, metadataAD);
+    } catch (err) {
+      throw new Error("Decryption failed on file metadata! The key is wrong or the metadata was tampered with.");
+    }
+  }
+
+  async function download(api, query, dropID, suite, keys, meta, grant) {
+    // 1. Fetch metadata (this consumes a view) and authenticate the manifest
+    setStatus("Contacting server...");
+    const dropMeta = await (await fetchOK(api + query)).json();
+    if (grant) {
+      // The grant's wrapped drop key comes with the counted download
+      try {
+        keys = await loadKeys(await unwrapGrantKey(grant.key, grant.id, fromBase64(dropMeta.grant_wrapped_key || "")));
+      } catch (err) {
+        throw new Error("Invalid key: " + (err.message || "the grant key does not open this link"));
+      }
+      meta = await openMetadata(suite, keys, dropMeta.metadata);
+      $("name").textContent = safeFileName(meta.name, dropID);
+      $("type").textContent = meta.mime_type || "unknown";
+      $("size").textContent = formatBytes(meta.size);
+      if (meta.mime_type === bundleMimeType) throw new Error(bundleMessage);
+    }
+    const fileName = safeFileName(meta.name, dropID);
+    if (!dropMeta.manifest) throw new Error("Drop has no manifest. The upload is incomplete or the server withheld it; refusing to download.");
+    if ((dropMeta.algorithm || "v1-aes-gcm") !== suite.id) throw new Error("The server changed the drop's algorithm. The drop has been tampered with.");
+
+    let manifest;
+    try {
+      manifest = await openJSON(suite, keys, dropMeta.manifest, manifestAD);
+    } catch (err) {
+      throw new Error("Decryption failed on manifest! The key is wrong or the manifest was tampered with.");
+    }
+    if (manifest.v !== 1) throw new Error("Unsupported manifest version " + manifest.v + ". Use the codedrop CLI.");
+    if (manifest.alg !== suite.id) throw new Error("Server reports algorithm " + suite.id + " but the manifest says " + manifest.alg + ". The drop has been tampered with.");
+    if (manifest.chunk_count !== dropMeta.chunk_count) throw new Error("Server reports " + dropMeta.chunk_count + " chunks but the manifest lists " + manifest.chunk_count + ". The drop has been tampered with.");
+    if (manifest.size > maxBrowserSize) throw new Error(tooLargeMessage);
+
+    // 2. Download, verify and decrypt each chunk into one buffer of the true size
+    const file = new Uint8Array(manifest.size);
+    let written = 0;
+    $("progress").max = manifest.chunk_count;
+    $("progress").hidden = false;
+    for (let i = 0; i < manifest.chunk_count; i++) {
+      setStatus("Decrypting chunk " + (i + 1) + "/" + manifest.chunk_count + "...");
+      const chunk = new Uint8Array(await (await fetchOK(api + "/chunk/" + i, { "X-Download-Token": dropMeta.download_token })).arrayBuffer());
+
+      if (toHex(await crypto.subtle.digest("SHA-256", chunk)) !== manifest.chunk_hashes[i]) {
+        throw new Error("Integrity check failed: chunk " + i + " does not match the manifest.");
+      }
+      let plaintext;
+      try {
+        plaintext = await open(suite, keys, chunk, null);
+      } catch (err) {
+        throw new Error("Decryption failed on chunk " + i + "! The data may be corrupted or the key is wrong.");
+      }
+
+      // Padding lives past the true length recorded in the manifest; drop it
+      plaintext = plaintext.subarray(0, Math.max(0, Math.min(plaintext.length, manifest.size - written)));
+      file.set(plaintext, written);
+      written += plaintext.length;
+      $("progress").value = i + 1;
+    }
+
+    // 3. Verify the whole file (and, for convergent drops, the key itself)
+    const fileHash = new Uint8Array(await crypto.subtle.digest("SHA-256", file));
+    if (written !== manifest.size || toHex(fileHash) !== manifest.file_hash) {
+      throw new Error("Integrity check failed: the decrypted file does not match the manifest.");
+    }
+    if (suite.keyMode === "convergent" && toHex(fileHash) !== toHex(keys.raw)) {
+      throw new Error("Integrity check failed: the file's SHA-256 does not match the convergent key.");
+    }
+
+    // 4. Hand the file to the browser
+    const url = URL.createObjectURL(new Blob([file], { type: "application/octet-stream" }));
+    const a = document.createElement("a");
+    a.href = url;
+    a.download = fileName;
+    document.body.appendChild(a);
+    a.click();
+    a.remove();
+    setTimeout(() => URL.revokeObjectURL(url), 60000);
+
+    $("progress").hidden = true;
+    let done = "Download complete: " + fileName;
+    if (manifest.signature) done += ". The sender signature is not checked in the browser; use codedrop pull to verify it.";
+    setStatus(done);
+  }
+
+  main().catch((err) => fail(err.message));
+})();
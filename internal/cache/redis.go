@@ -36,7 +36,19 @@ func NewRedisClient() (*RedisClient, error) {
 }
 
 // IncrementAndCheck atomically increments the download count and checks if it exceeds the max.
-func (r *RedisClient) IncrementAndCheck(ctx context.Context, dropID string, maxDownloads int) (bool, error) {
+// When allowed, it also returns how many downloads are left, so the caller knows
+// when it has handed out the last one.
+func (r *RedisClient) IncrementAndCheck(ctx context.Context, dropID string, maxDownloads int) (bool, int, error) {
+	return r.incrementAndCheck(ctx, fmt.Sprintf("drop:%s:downloads", dropID), maxDownloads)
+}
+
+// GrantIncrementAndCheck is IncrementAndCheck for one access grant of a drop
+// (push --recipients). Every grant has its own counter.
+func (r *RedisClient) GrantIncrementAndCheck(ctx context.Context, grantID string, maxDownloads int) (bool, int, error) {
+	return r.incrementAndCheck(ctx, fmt.Sprintf("grant:%s:downloads", grantID), maxDownloads)
+}
+
+func (r *RedisClient) incrementAndCheck(ctx context.Context, key string, maxDownloads int) (bool, int, error) {
 	// The Lua Script
 	// KEYS[1] = The Redis key for this drop's counter (e.g., "drop:123:downloads")
 	// ARGV[1] = The maximum allowed downloads
@@ -54,24 +66,142 @@ func (r *RedisClient) IncrementAndCheck(ctx context.Context, dropID string, maxD
 		end
 		
 		if current > max_downloads then
-			return 0 -- Failed / Rejected
+			return -1 -- Failed / Rejected
 		end
 		
-		return 1 -- Success / Allowed
+		return max_downloads - current -- Success / Allowed, with the downloads left
 	`)
 
-	key := fmt.Sprintf("drop:%s:downloads", dropID)
-	
 	// Run the script atomically
 	result, err := script.Run(ctx, r.client, []string{key}, maxDownloads).Result()
 	if err != nil {
-		return false, fmt.Errorf("redis script error: %w", err)
+		return false, 0, fmt.Errorf("redis script error: %w", err)
+	}
+
+	// -1 means rejected, anything else is allowed
+	remaining := int(result.(int64))
+	return remaining >= 0, remaining, nil
+}
+
+// DownloadCount returns how many downloads a drop has used so far, without changing it.
+func (r *RedisClient) DownloadCount(ctx context.Context, dropID string) (int, error) {
+	return r.downloadCount(ctx, fmt.Sprintf("drop:%s:downloads", dropID))
+}
+
+// GrantDownloadCount returns how many downloads an access grant has used so far.
+func (r *RedisClient) GrantDownloadCount(ctx context.Context, grantID string) (int, error) {
+	return r.downloadCount(ctx, fmt.Sprintf("grant:%s:downloads", grantID))
+}
+
+func (r *RedisClient) downloadCount(ctx context.Context, key string) (int, error) {
+	count, err := r.client.Get(ctx, key).Int()
+	if err == redis.Nil {
+		return 0, nil // Never downloaded
+	}
+	if err != nil {
+		return 0, fmt.Errorf("redis get error: %w", err)
+	}
+	return count, nil
+}
+
+// CompleteDownload counts a download of the drop that was served to the end
+// and returns how many have completed so far.
+func (r *RedisClient) CompleteDownload(ctx context.Context, dropID string) (int, error) {
+	key := fmt.Sprintf("drop:%s:completed", dropID)
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis incr error: %w", err)
+	}
+	if count == 1 {
+		r.client.Expire(ctx, key, 24*time.Hour) // Drops live at most 24 hours
+	}
+	return int(count), nil
+}
+
+// IssueDownloadToken records a download the metadata endpoint counted, for
+// the drop and, through a grant link, the grant it was counted against.
+func (r *RedisClient) IssueDownloadToken(ctx context.Context, token, dropID, grantID string, ttl time.Duration) error {
+	key := "download:" + token
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, key, "drop", dropID, "grant", grantID)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis hset error: %w", err)
+	}
+	return nil
+}
+
+// DownloadToken returns the drop and grant a download token was issued for.
+// An unknown or expired token has no drop.
+func (r *RedisClient) DownloadToken(ctx context.Context, token string) (dropID, grantID string, err error) {
+	fields, err := r.client.HGetAll(ctx, "download:"+token).Result()
+	if err != nil {
+		return "", "", fmt.Errorf("redis hgetall error: %w", err)
 	}
+	return fields["drop"], fields["grant"], nil
+}
 
-	// 1 means allowed, 0 means rejected
+// CompleteDownloadToken marks the download a token was issued for as served
+// to the end. It reports true only the first time, so a download completes
+// once however often its last chunk is fetched.
+func (r *RedisClient) CompleteDownloadToken(ctx context.Context, token string) (bool, error) {
+	script := redis.NewScript(`
+		if redis.call("EXISTS", KEYS[1]) == 0 then
+			return 0
+		end
+		return redis.call("HSETNX", KEYS[1], "completed", 1)
+	`)
+
+	result, err := script.Run(ctx, r.client, []string{"download:" + token}).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis script error: %w", err)
+	}
 	return result.(int64) == 1, nil
 }
 
+// burnQueue is the Redis list of drops waiting to be burned by the garbage collector
+const burnQueue = "gc:burn"
+
+// EnqueueBurn queues a drop whose downloads are all used up for deletion.
+// The garbage collector picks it up with NextBurn, off the request path.
+func (r *RedisClient) EnqueueBurn(ctx context.Context, dropID string) error {
+	if err := r.client.LPush(ctx, burnQueue, dropID).Err(); err != nil {
+		return fmt.Errorf("redis lpush error: %w", err)
+	}
+	return nil
+}
+
+// NextBurn waits up to timeout for a queued drop and returns its ID, or ""
+// when none arrived.
+func (r *RedisClient) NextBurn(ctx context.Context, timeout time.Duration) (string, error) {
+	result, err := r.client.BRPop(ctx, timeout, burnQueue).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("redis brpop error: %w", err)
+	}
+	return result[1], nil // [list name, value]
+}
+
+// AllowRequest is a fixed-window rate limiter: it counts a request against key
+// and reports whether fewer than limit requests were made in the current window.
+func (r *RedisClient) AllowRequest(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
+	script := redis.NewScript(`
+		local current = redis.call("INCR", KEYS[1])
+		if current == 1 then
+			redis.call("PEXPIRE", KEYS[1], ARGV[1])
+		end
+		return current
+	`)
+
+	result, err := script.Run(ctx, r.client, []string{"ratelimit:" + key}, window.Milliseconds()).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis script error: %w", err)
+	}
+	return result.(int64) <= int64(limit), nil
+}
+
 // Helper to get env vars
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
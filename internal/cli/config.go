@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Config is the optional CLI configuration file, by default
+// ~/.config/codedrop/config.json (override with CODEDROP_CONFIG).
+type Config struct {
+	// TeamSecret is a Base64 secret shared by a team. When set, push derives
+	// convergent keys from it so only team members can derive or confirm them.
+	TeamSecret string `json:"team_secret,omitempty"`
+
+	// SigningKey is used when push is run without --sign. A private key file,
+	// or a .pub file whose private key is held by ssh-agent.
+	SigningKey string `json:"signing_key,omitempty"`
+
+	// EscrowRecipient is an organization recovery public key (age1..., cdpq1...
+	// or ssh-ed25519). When set, push also wraps every drop key to it, and an
+	// admin holding the private key can run `codedrop recover`.
+	EscrowRecipient string `json:"escrow_recipient,omitempty"`
+
+	// TrustedSenders overrides the default ~/.config/codedrop/trusted_senders.
+	TrustedSenders string `json:"trusted_senders,omitempty"`
+}
+
+// configDir returns the directory holding the CLI's config and key files.
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "codedrop"), nil
+}
+
+// loadConfig reads the config file. A missing file is not an error.
+func loadConfig() (*Config, error) {
+	path := os.Getenv("CODEDROP_CONFIG")
+	if path == "" {
+		dir, err := configDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "config.json")
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// teamSecret decodes the configured team secret, or returns nil if there is none.
+func (c *Config) teamSecret() ([]byte, error) {
+	if c.TeamSecret == "" {
+		return nil, nil
+	}
+	secret, err := base64.StdEncoding.DecodeString(c.TeamSecret)
+	if err != nil {
+		return nil, fmt.Errorf("team_secret in config is not valid Base64: %w", err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+// dropURL is a parsed share link: http://host/drop/<id>#k=<key>, or
+// #w=<wrapped key> for passphrase-protected drops. Drops pushed with --to have
+// no fragment at all; their key is wrapped to the recipients on the server.
+// Split drops (push --split) have one link per key share, #s=<share>, and
+// drops pushed with --recipients one link per person, #g=<grant key>.
+type dropURL struct {
+	BaseURL    string // e.g. http://localhost:8080
+	DropID     string
+	EncodedKey string   // Base64 key from the fragment, never sent to the server
+	WrappedKey string   // Base64 passphrase-wrapped key, set instead of EncodedKey
+	Shares     []string // Base64 key shares, set instead of EncodedKey
+	GrantKey   []byte   // Access grant key, set instead of EncodedKey
+	GrantID    string   // Derived from GrantKey; the server counts downloads against it
+}
+
+// parseDropURL splits a share link into the server, the drop ID and the key fragment.
+func parseDropURL(inputURL string) (*dropURL, error) {
+	parsedURL, err := url.Parse(inputURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL format: %w", err)
+	}
+
+	// Extract Drop ID from Path (e.g., /drop/1234-5678)
+	pathParts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if len(pathParts) != 2 || pathParts[0] != "drop" {
+		return nil, fmt.Errorf("invalid URL path. Expected format: http://host/drop/<id>#k=<key>")
+	}
+
+	link := &dropURL{
+		BaseURL: fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host),
+		DropID:  pathParts[1],
+	}
+
+	// Extract Key from Fragment (e.g., k=base64key, w=wrappedkey or g=grantkey)
+	fragment := parsedURL.Fragment
+	switch {
+	case strings.HasPrefix(fragment, "k="):
+		link.EncodedKey = strings.TrimPrefix(fragment, "k=")
+	case strings.HasPrefix(fragment, "w="):
+		link.WrappedKey = strings.TrimPrefix(fragment, "w=")
+	case strings.HasPrefix(fragment, "s="):
+		link.Shares = []string{strings.TrimPrefix(fragment, "s=")}
+	case strings.HasPrefix(fragment, "g="):
+		grantKey, err := base64.URLEncoding.Strict().DecodeString(strings.TrimPrefix(fragment, "g="))
+		if err != nil || len(grantKey) != crypto.GrantKeySize {
+			return nil, fmt.Errorf("invalid grant key in URL fragment (#g=...)")
+		}
+		link.GrantKey = grantKey
+		link.GrantID = crypto.GrantID(grantKey)
+	case fragment == "":
+		// Recipient drop; resolveDropKey looks for wrapped keys on the server
+	default:
+		return nil, fmt.Errorf("missing decryption key in URL fragment (#k=...)")
+	}
+
+	return link, nil
+}
+
+// parseDropURLs parses one share link, or several share links for the same
+// drop, which are merged into a single dropURL holding all the shares.
+func parseDropURLs(inputs []string) (*dropURL, error) {
+	link, err := parseDropURL(inputs[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, input := range inputs[1:] {
+		share, err := parseDropURL(input)
+		if err != nil {
+			return nil, err
+		}
+		if err := link.addShare(share); err != nil {
+			return nil, err
+		}
+	}
+	return link, nil
+}
+
+// addShare merges the key share from another link for the same drop.
+func (l *dropURL) addShare(other *dropURL) error {
+	if len(l.Shares) == 0 || len(other.Shares) == 0 {
+		return fmt.Errorf("multiple URLs are only accepted for split drops (#s=...)")
+	}
+	if other.DropID != l.DropID {
+		return fmt.Errorf("share is for drop %s, not %s", other.DropID, l.DropID)
+	}
+	l.Shares = append(l.Shares, other.Shares...)
+	return nil
+}
+
+// safeFileName strips any directory components from a sender-supplied name so a
+// malicious drop cannot write outside the current directory.
+func safeFileName(name, dropID string) string {
+	name = path.Base(path.Clean("/" + strings.ReplaceAll(name, "\\", "/")))
+	if name == "/" || name == "." || name == "" {
+		return "drop-" + dropID
+	}
+	return name
+}
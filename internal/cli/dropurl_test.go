@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestParseDropURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+		check   func(*dropURL) bool
+	}{
+		{"http://localhost:8080/drop/abc#k=AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=", false, func(l *dropURL) bool {
+			return l.BaseURL == "http://localhost:8080" && l.DropID == "abc" && l.EncodedKey == "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+		}},
+		{"https://drop.example.com/drop/abc#w=wrapped", false, func(l *dropURL) bool {
+			return l.WrappedKey == "wrapped" && l.EncodedKey == ""
+		}},
+		{"https://drop.example.com/drop/abc#s=share", false, func(l *dropURL) bool {
+			return len(l.Shares) == 1 && l.Shares[0] == "share"
+		}},
+		{"https://drop.example.com/drop/abc", false, func(l *dropURL) bool {
+			return l.EncodedKey == "" && l.WrappedKey == "" && len(l.Shares) == 0
+		}},
+		{"https://drop.example.com/drop/abc#g=AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=", false, func(l *dropURL) bool {
+			return len(l.GrantKey) == 32 && len(l.GrantID) == 32 && l.EncodedKey == ""
+		}},
+		{"https://drop.example.com/drop/abc#g=AAECAwQF", true, nil},
+		{"https://drop.example.com/drop/abc#x=1", true, nil},
+		{"https://drop.example.com/files/abc#k=key", true, nil},
+		{"https://drop.example.com/drop/abc/extra#k=key", true, nil},
+		{"::not a url", true, nil},
+	}
+
+	for _, tt := range tests {
+		link, err := parseDropURL(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDropURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !tt.check(link) {
+			t.Errorf("parseDropURL(%q) = %+v", tt.url, link)
+		}
+	}
+}
+
+func FuzzParseDropURL(f *testing.F) {
+	f.Add("http://localhost:8080/drop/abc#k=AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+	f.Add("http://localhost:8080/drop/abc#w=AAAA")
+	f.Add("http://localhost:8080/drop/abc#s=AgE")
+	f.Add("http://localhost:8080/drop/abc#g=AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+	f.Add("http://localhost:8080/drop/abc")
+	f.Add("drop/abc#k=")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		link, err := parseDropURL(input)
+		if err != nil {
+			return
+		}
+		if link.DropID == "" && !strings.Contains(input, "/drop/") {
+			t.Errorf("Accepted %q without a drop path", input)
+		}
+		// Key material only ever comes from the fragment, which is never sent to the server
+		fragment := ""
+		if i := strings.IndexByte(input, '#'); i >= 0 {
+			fragment = input[i+1:]
+		}
+		for _, secret := range append([]string{link.EncodedKey, link.WrappedKey, base64.URLEncoding.EncodeToString(link.GrantKey)}, link.Shares...) {
+			if secret != "" && !strings.Contains(fragment, secret) {
+				t.Errorf("Key material %q in %q did not come from the fragment", secret, input)
+			}
+		}
+	})
+}
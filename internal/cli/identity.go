@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+// identityFiles is set by --identity on commands that may need to unwrap a key.
+var identityFiles []string
+
+// defaultIdentityFile is where keygen writes, and where pull looks first.
+func defaultIdentityFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "identity"), nil
+}
+
+// loadIdentities reads the identities given with --identity. Without the flag it
+// falls back to the keygen identity and ~/.ssh/id_ed25519, whichever exist.
+func loadIdentities() ([]crypto.Identity, error) {
+	paths := identityFiles
+	explicit := len(paths) > 0
+	if !explicit {
+		if path, err := defaultIdentityFile(); err == nil {
+			paths = append(paths, path)
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			paths = append(paths, filepath.Join(home, ".ssh", "id_ed25519"))
+		}
+	}
+
+	var identities []crypto.Identity
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read identity file: %w", err)
+		}
+
+		ids, err := crypto.ParseIdentities(data, func() ([]byte, error) {
+			fmt.Printf("%s is encrypted.\n", path)
+			return readPassphrase(false)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse identity file %s: %w", path, err)
+		}
+		identities = append(identities, ids...)
+	}
+
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("this drop is encrypted to recipients, but no identity was found (run 'codedrop keygen' or pass --identity)")
+	}
+	return identities, nil
+}
+
+// parseRecipients accepts recipient strings (age1..., cdpq1... or "ssh-ed25519 AAAA...")
+// and paths to files listing one recipient per line.
+func parseRecipients(values []string) ([]crypto.Recipient, error) {
+	var recipients []crypto.Recipient
+	for _, value := range values {
+		if strings.HasPrefix(value, "age1") || strings.HasPrefix(value, "cdpq1") || strings.HasPrefix(value, "ssh-") {
+			r, err := crypto.ParseRecipient(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid recipient %q: %w", value, err)
+			}
+			recipients = append(recipients, r)
+			continue
+		}
+
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither a recipient nor a readable file: %w", value, err)
+		}
+		for n, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			r, err := crypto.ParseRecipient(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid recipient: %w", value, n+1, err)
+			}
+			recipients = append(recipients, r)
+		}
+	}
+	return recipients, nil
+}
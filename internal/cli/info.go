@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sumanthd032/codedrop/internal/client"
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info [url or short code] [share urls...]",
+	Short: "Show details about a drop without downloading it",
+	Long: `Decrypts the drop's metadata locally and shows its name, type, size and
+remaining views. This does not consume a download.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		args, err := expandShortCodes(cmd, args)
+		if err != nil {
+			fmt.Printf("Short code failed: %v\n", err)
+			os.Exit(1)
+		}
+		link, err := parseDropURLs(args)
+		if err != nil {
+			fmt.Printf("Invalid URL: %v\n", err)
+			os.Exit(1)
+		}
+
+		// A grant link's key only comes with a download, so its file details stay
+		// encrypted here; the rest is what the server knows about the drop
+		api := client.NewAPIClient(link.BaseURL)
+		var key []byte
+		if link.GrantKey == nil {
+			key, err = resolveDropKey(link, api)
+			if err != nil {
+				fmt.Printf("Invalid key: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		info, err := fetchDropInfo(link, api)
+		if err != nil {
+			fmt.Printf("Failed to fetch drop info: %v\n", err)
+			os.Exit(1)
+		}
+
+		suite, err := crypto.LookupSuite(info.Algorithm)
+		if err != nil {
+			fmt.Printf("%v. Try upgrading codedrop.\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("\n=== CodeDrop Info ===")
+		if key == nil {
+			fmt.Println("File Name   : (encrypted; a grant link's key comes with the download)")
+			fmt.Printf("Stored Size : %s\n", formatBytes(info.FileSize))
+		} else {
+			printFileDetails(link, suite, key, info)
+		}
+		fmt.Printf("Cipher      : %s\n", suite.Cipher)
+		fmt.Printf("Chunks      : %d\n", info.ChunkCount)
+		if len(info.Manifest) == 0 {
+			fmt.Println("Status      : upload incomplete (no manifest yet)")
+		}
+		if info.NotBefore != nil && time.Now().Before(*info.NotBefore) {
+			fmt.Printf("Opens At    : %s (embargoed; pull --wait waits for it)\n", info.NotBefore.Local().Format("Jan 02, 2006 15:04:05 MST"))
+		}
+		fmt.Printf("Expires At  : %s\n", info.ExpiresAt.Local().Format("Jan 02, 2006 15:04:05 MST"))
+		fmt.Printf("Views Left  : %d of %d\n", info.MaxDownloads-info.Downloads, info.MaxDownloads)
+		fmt.Println("=====================")
+	},
+}
+
+// printFileDetails shows the name, type and size from the encrypted metadata,
+// and a bundle's files from the encrypted manifest
+func printFileDetails(link *dropURL, suite *crypto.Suite, key []byte, info *client.DropInfoResponse) {
+	fileMeta, err := crypto.OpenMetadata(suite, key, info.Metadata)
+	if err != nil {
+		fmt.Printf("Decryption failed on file metadata! The key is wrong or the metadata was tampered with: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("File Name   : %s\n", safeFileName(fileMeta.Name, link.DropID))
+	if fileMeta.MimeType != "" {
+		fmt.Printf("MIME Type   : %s\n", fileMeta.MimeType)
+	}
+	fmt.Printf("Size        : %s\n", formatBytes(fileMeta.Size))
+	if !fileMeta.ModTime.IsZero() {
+		fmt.Printf("Modified    : %s\n", fileMeta.ModTime.Local().Format("Jan 02, 2006 15:04:05 MST"))
+	}
+	// A bundle's files are listed in the encrypted manifest
+	if fileMeta.MimeType == crypto.BundleMimeType && len(info.Manifest) > 0 {
+		manifest, err := crypto.OpenManifest(suite, key, info.Manifest)
+		if err != nil {
+			fmt.Printf("Decryption failed on manifest! The key is wrong or the manifest was tampered with: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Files       :")
+		for _, entry := range manifest.Entries {
+			fmt.Printf("  %s (%s)\n", safeFileName(entry.Name, link.DropID), formatBytes(entry.Size))
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+	infoCmd.Flags().StringArrayVarP(&identityFiles, "identity", "i", nil, "Identity file for drops encrypted with push --to (repeatable)")
+	infoCmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "Read the passphrase for a protected drop from this file")
+}
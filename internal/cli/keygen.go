@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+var (
+	keygenOutput string
+	keygenPQ     bool
+)
+
+var keygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Create an identity for receiving drops with push --to",
+	Long: `Generates an X25519 key pair in the age format. The private identity is
+written to ~/.config/codedrop/identity (or --output) and the public recipient
+(age1...) is printed; give that to people who send you drops.
+
+With --pq the key pair is hybrid ML-KEM-768 + X25519 (cdpq1...), so drops
+wrapped to it stay confidential even against a future quantum computer.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := keygenOutput
+		if path == "" {
+			var err error
+			path, err = defaultIdentityFile()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		var identity interface {
+			crypto.Identity
+			String() string
+		}
+		var err error
+		if keygenPQ {
+			identity, err = crypto.GenerateHybridIdentity()
+		} else {
+			identity, err = crypto.GenerateX25519Identity()
+		}
+		if err != nil {
+			fmt.Printf("Error generating identity: %v\n", err)
+			os.Exit(1)
+		}
+		recipient := identity.Recipient().String()
+
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			fmt.Printf("Error creating directory: %v\n", err)
+			os.Exit(1)
+		}
+		// O_EXCL: never overwrite an existing identity, drops wrapped to it would be lost
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			fmt.Printf("Error writing identity: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		fmt.Fprintf(file, "# created: %s\n", time.Now().Format(time.RFC3339))
+		fmt.Fprintf(file, "# public key: %s\n", recipient)
+		if _, err := fmt.Fprintf(file, "%s\n", identity); err != nil {
+			fmt.Printf("Error writing identity: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Identity written to %s\n", path)
+		fmt.Printf("Public key: %s\n", recipient)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(keygenCmd)
+	keygenCmd.Flags().BoolVar(&keygenPQ, "pq", false, "Generate a hybrid post-quantum (ML-KEM-768 + X25519) key pair")
+	keygenCmd.Flags().StringVarP(&keygenOutput, "output", "o", "", "Write the identity to this file instead of the default location")
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sumanthd032/codedrop/internal/client"
+	"github.com/sumanthd032/codedrop/internal/crypto"
+	"golang.org/x/term"
+)
+
+// resolveDropKey turns the key material in a share link into the raw drop key.
+// Plain links carry the key itself; passphrase links carry a wrapped key that is
+// opened with the passphrase and the salt stored on the server. Links without a
+// fragment belong to recipient drops, whose key is unwrapped with a local
+// identity, and split drops combine key shares. Server-side data is read
+// through the info endpoint, so a wrong passphrase or identity never consumes
+// a download. Grant links are refused: their key only comes with a download.
+func resolveDropKey(link *dropURL, api *client.APIClient) ([]byte, error) {
+	if link.EncodedKey != "" {
+		return crypto.DecodeKey(link.EncodedKey)
+	}
+	if len(link.Shares) > 0 {
+		return resolveShareKey(link)
+	}
+	if link.GrantKey != nil {
+		return nil, errGrantKeyWithDownload
+	}
+	if link.WrappedKey == "" {
+		return resolveRecipientKey(link, api)
+	}
+	key, _, err := resolvePassphraseKey(link, api)
+	return key, err
+}
+
+// resolvePassphraseKey unwraps a passphrase link's key. It also returns the
+// wrapping key, so reshare can wrap the same key for a new drop ID.
+func resolvePassphraseKey(link *dropURL, api *client.APIClient) (key, wrappingKey []byte, err error) {
+	wrapped, err := base64.URLEncoding.DecodeString(link.WrappedKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid base64 wrapped key: %w", err)
+	}
+
+	info, err := api.GetDropInfo(link.DropID)
+	if err != nil {
+		return nil, nil, err
+	}
+	params, err := crypto.ParseKDFParams(info.EncryptionSalt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	passphrase, err := readPassphrase(false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fmt.Println("Deriving key from passphrase...")
+	wrappingKey = params.DeriveWrappingKey(passphrase)
+	key, err = crypto.UnwrapKey(wrappingKey, wrapped, link.DropID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w (no download was consumed)", err)
+	}
+	return key, wrappingKey, nil
+}
+
+// errGrantKeyWithDownload is returned for a grant link (push --recipients)
+// where no download is made: the server hands out the grant's wrapped key
+// only with a counted download, or to the drop's owner.
+var errGrantKeyWithDownload = errors.New("a grant link's key is only released with a download (codedrop pull)")
+
+// resolveOwnerGrantKey unwraps the drop key stored with an access grant,
+// fetched with the owner token instead of a download.
+func resolveOwnerGrantKey(link *dropURL, api *client.APIClient, ownerToken string) ([]byte, error) {
+	wrapped, err := api.GetGrantKey(link.DropID, link.GrantID, ownerToken)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.UnwrapGrantKey(link.GrantKey, wrapped)
+}
+
+// fetchDropInfo reads the drop through the info endpoint, through its access
+// grant for a grant link, so the download counts shown are the link's own.
+func fetchDropInfo(link *dropURL, api *client.APIClient) (*client.DropInfoResponse, error) {
+	if link.GrantID != "" {
+		return api.GetGrantInfo(link.DropID, link.GrantID)
+	}
+	return api.GetDropInfo(link.DropID)
+}
+
+// resolveRecipientKey unwraps the drop key with one of the local identities.
+func resolveRecipientKey(link *dropURL, api *client.APIClient) ([]byte, error) {
+	info, err := api.GetDropInfo(link.DropID)
+	if err != nil {
+		return nil, err
+	}
+	if len(info.WrappedKeys) == 0 {
+		return nil, fmt.Errorf("missing decryption key in URL fragment (#k=...)")
+	}
+	stanzas, err := crypto.ParseStanzas(info.WrappedKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := loadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.UnwrapStanzas(identities, stanzas)
+	if err != nil {
+		return nil, fmt.Errorf("%w (no download was consumed)", err)
+	}
+	return key, nil
+}
+
+// resolveShareKey combines the key shares from the given links, prompting for
+// more share URLs until the threshold is met.
+func resolveShareKey(link *dropURL) ([]byte, error) {
+	var shares [][]byte
+	for _, encoded := range link.Shares {
+		share, err := base64.URLEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 key share: %w", err)
+		}
+		shares = append(shares, share)
+	}
+	threshold, err := crypto.ShareThreshold(shares[0])
+	if err != nil {
+		return nil, err
+	}
+
+	for len(shares) < threshold {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return nil, fmt.Errorf("this drop needs %d key shares, got %d: pass all share URLs", threshold, len(shares))
+		}
+		input, err := promptSecret(fmt.Sprintf("Share URL (%d of %d): ", len(shares)+1, threshold))
+		if err != nil {
+			return nil, err
+		}
+		other, err := parseDropURL(strings.TrimSpace(string(input)))
+		if err != nil {
+			return nil, err
+		}
+		if err := link.addShare(other); err != nil {
+			return nil, err
+		}
+		share, err := base64.URLEncoding.DecodeString(other.Shares[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 key share: %w", err)
+		}
+		shares = append(shares, share)
+	}
+
+	return crypto.CombineShares(shares)
+}
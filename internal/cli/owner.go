@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sumanthd032/codedrop/internal/client"
+)
+
+// ownedDrop is a drop created from this machine. The owner token is returned
+// once, when the drop is created, and is the only way to reshare it.
+type ownedDrop struct {
+	Server     string    `json:"server"`
+	OwnerToken string    `json:"owner_token"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ownedDropsFile returns ~/.config/codedrop/owned_drops.json, keyed by drop ID.
+func ownedDropsFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "owned_drops.json"), nil
+}
+
+// loadOwnedDrops reads the owned drops file. A missing file is not an error.
+func loadOwnedDrops() (map[string]ownedDrop, error) {
+	path, err := ownedDropsFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]ownedDrop{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	drops := map[string]ownedDrop{}
+	if err := json.Unmarshal(data, &drops); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return drops, nil
+}
+
+// saveOwnerToken records the owner token of a newly created drop, forgetting
+// drops that have since expired.
+func saveOwnerToken(serverURL string, resp *client.CreateDropResponse) error {
+	if resp.OwnerToken == "" {
+		return nil // Older servers don't issue one
+	}
+	drops, err := loadOwnedDrops()
+	if err != nil {
+		return err
+	}
+	for id, d := range drops {
+		if time.Now().After(d.ExpiresAt) {
+			delete(drops, id)
+		}
+	}
+	drops[resp.DropID] = ownedDrop{Server: serverURL, OwnerToken: resp.OwnerToken, ExpiresAt: resp.ExpiresAt}
+
+	data, err := json.MarshalIndent(drops, "", "  ")
+	if err != nil {
+		return err
+	}
+	path, err := ownedDropsFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// lookupOwnerToken returns the saved owner token for a drop.
+func lookupOwnerToken(dropID string) (string, error) {
+	drops, err := loadOwnedDrops()
+	if err != nil {
+		return "", err
+	}
+	d, ok := drops[dropID]
+	if !ok {
+		return "", fmt.Errorf("no owner token for drop %s on this machine (pass --owner-token)", dropID)
+	}
+	return d.OwnerToken, nil
+}
+
+// resolveOwnedDrop finds the drop an owner command is about, given its URL or
+// just its ID, and the owner token to act on it with. A bare ID must be one
+// this machine created, since that is where its server is recorded.
+func resolveOwnedDrop(arg, ownerToken string) (*dropURL, string, error) {
+	if !strings.Contains(arg, "/") {
+		drops, err := loadOwnedDrops()
+		if err != nil {
+			return nil, "", err
+		}
+		d, ok := drops[arg]
+		if !ok {
+			return nil, "", fmt.Errorf("drop %s was not created on this machine; pass its URL instead", arg)
+		}
+		if ownerToken == "" {
+			ownerToken = d.OwnerToken
+		}
+		return &dropURL{BaseURL: d.Server, DropID: arg}, ownerToken, nil
+	}
+
+	link, err := parseDropURL(arg)
+	if err != nil {
+		return nil, "", err
+	}
+	if ownerToken == "" {
+		ownerToken, err = lookupOwnerToken(link.DropID)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return link, ownerToken, nil
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// passphraseFile is set by --passphrase-file on commands that need one.
+var passphraseFile string
+
+// readPassphrase gets a passphrase from --passphrase-file, the CODEDROP_PASSPHRASE
+// environment variable, or an interactive prompt, in that order. With confirm set,
+// an interactive prompt asks twice.
+func readPassphrase(confirm bool) ([]byte, error) {
+	if passphraseFile != "" {
+		data, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+		return nonEmptyPassphrase([]byte(strings.TrimRight(string(data), "\r\n")))
+	}
+
+	if env := os.Getenv("CODEDROP_PASSPHRASE"); env != "" {
+		return []byte(env), nil
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("a passphrase is required: use --passphrase-file or set CODEDROP_PASSPHRASE")
+	}
+
+	passphrase, err := promptSecret("Passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if confirm {
+		again, err := promptSecret("Confirm passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+	return nonEmptyPassphrase(passphrase)
+}
+
+// promptSecret reads a line from the terminal without echoing it.
+func promptSecret(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return secret, nil
+}
+
+func nonEmptyPassphrase(passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+	return passphrase, nil
+}
@@ -1,113 +1,416 @@
 package cli
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"net/url"
 	"os"
-	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/sumanthd032/codedrop/internal/client"
 	"github.com/sumanthd032/codedrop/internal/crypto"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	requireSigned bool
+	pullOnly      []string
+	pullWait      bool
 )
 
 var pullCmd = &cobra.Command{
-	Use:   "pull [url]",
+	Use:   "pull [url or short code] [share urls...]",
 	Short: "Download and decrypt a file from CodeDrop",
-	Args:  cobra.ExactArgs(1),
+	Long: `Downloads, verifies and decrypts a drop into the current directory. A bundle
+pushed as several files is extracted in full, or only the files named with --only.
+An embargoed drop (push --not-before) can't be pulled before its time; --wait
+waits for it to open instead of failing.`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		inputURL := args[0]
 
-		// 1. Parse the URL
-		parsedURL, err := url.Parse(inputURL)
+		// 1. Parse the URL (or the share URLs of a split drop, or a short code)
+		args, err := expandShortCodes(cmd, args)
 		if err != nil {
-			fmt.Printf("Invalid URL format: %v\n", err)
+			fmt.Printf("Short code failed: %v\n", err)
 			os.Exit(1)
 		}
+		link, err := parseDropURLs(args)
+		if err != nil {
+			fmt.Printf("Invalid URL: %v\n", err)
+			os.Exit(1)
+		}
+		dropID := link.DropID
 
-		// Extract Base URL (e.g., http://localhost:8080)
-		baseURL := fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
+		// 2. Decode the Key (prompting for a passphrase if the link is protected).
+		// A grant link's key only comes with the download in step 3, so the
+		// checks below can't spare it a download; they run again after step 3.
+		fmt.Println("Decoding decryption key...")
+		api := client.NewAPIClient(link.BaseURL)
+		var key []byte
+		if link.GrantKey == nil {
+			key, err = resolveDropKey(link, api)
+			if err != nil {
+				fmt.Printf("Invalid key: %v\n", err)
+				os.Exit(1)
+			}
+		}
 
-		// Extract Drop ID from Path (e.g., /drop/1234-5678)
-		pathParts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
-		if len(pathParts) != 2 || pathParts[0] != "drop" {
-			fmt.Println("Invalid URL path. Expected format: http://host/drop/<id>#k=<key>")
-			os.Exit(1)
+		// With --require-signed, vet the sender through the info endpoint first so
+		// a refused drop does not cost a download. The check is repeated below.
+		if requireSigned && key != nil {
+			if err := precheckSender(api, dropID, key); err != nil {
+				fmt.Printf("Sender verification failed: %v (no download was consumed)\n", err)
+				os.Exit(1)
+			}
 		}
-		dropID := pathParts[1]
 
-		// Extract Key from Fragment (e.g., k=base64key)
-		fragment := parsedURL.Fragment
-		if !strings.HasPrefix(fragment, "k=") {
-			fmt.Println("Missing decryption key in URL fragment (#k=...).")
+		// Likewise make sure every --only name exists before spending the download
+		if len(pullOnly) > 0 && key != nil {
+			if err := precheckOnly(api, dropID, key); err != nil {
+				fmt.Printf("Error: %v (no download was consumed)\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// 3. Fetch Metadata. An embargoed drop says when it opens; with --wait,
+		// sleep until then and ask again.
+		fmt.Println("Contacting server for metadata...")
+		var meta *client.GetDropMetadataResponse
+		var early *client.NotYetAvailableError
+		for {
+			if link.GrantID != "" {
+				meta, err = api.GetGrantMetadata(dropID, link.GrantID)
+			} else {
+				meta, err = api.GetDropMetadata(dropID)
+			}
+			if !pullWait || !errors.As(err, &early) {
+				break
+			}
+			fmt.Printf("Drop opens at %s, waiting...\n", early.At.Local().Format("Jan 02, 2006 15:04:05 MST"))
+			time.Sleep(max(time.Until(early.At), time.Second)) // At least a second, in case our clock is ahead
+		}
+		if errors.As(err, &early) {
+			fmt.Printf("Failed to fetch metadata: %v (pull --wait waits for it)\n", err)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Failed to fetch metadata: %v\n", err)
 			os.Exit(1)
 		}
-		encodedKey := strings.TrimPrefix(fragment, "k=")
+		if link.GrantKey != nil {
+			key, err = crypto.UnwrapGrantKey(link.GrantKey, meta.GrantWrappedKey)
+			if err != nil {
+				fmt.Printf("Invalid key: %v\n", err)
+				os.Exit(1)
+			}
+		}
 
-		// 2. Decode the Key
-		fmt.Println("Decoding decryption key...")
-		key, err := crypto.DecodeKey(encodedKey)
+		// 4. Authenticate the manifest BEFORE trusting anything else the server says
+		if len(meta.Manifest) == 0 {
+			fmt.Println("Drop has no manifest. The upload is incomplete or the server withheld it; refusing to download.")
+			os.Exit(1)
+		}
+		// The server's algorithm picks the cipher suite; the manifest's authenticated copy must agree
+		suite, err := crypto.LookupSuite(meta.Algorithm)
 		if err != nil {
-			fmt.Printf("Invalid key: %v\n", err)
+			fmt.Printf("%v. Try upgrading codedrop.\n", err)
+			os.Exit(1)
+		}
+		manifest, err := crypto.OpenManifest(suite, key, meta.Manifest)
+		if err != nil {
+			fmt.Printf("Decryption failed on manifest! The key is wrong or the manifest was tampered with: %v\n", err)
+			os.Exit(1)
+		}
+		if manifest.Algorithm != suite.ID {
+			fmt.Printf("Server reports algorithm %q but the manifest says %q. The drop has been tampered with.\n", suite.ID, manifest.Algorithm)
+			os.Exit(1)
+		}
+		if manifest.ChunkCount != meta.ChunkCount {
+			fmt.Printf("Server reports %d chunks but the manifest lists %d. The drop has been tampered with.\n", meta.ChunkCount, manifest.ChunkCount)
 			os.Exit(1)
 		}
 
-		// 3. Fetch Metadata
-		fmt.Println("Contacting server for metadata...")
-		api := client.NewAPIClient(baseURL)
-		
-		meta, err := api.GetDropMetadata(dropID)
+		// The file name and other details are encrypted; the server never saw them
+		fileMeta, err := crypto.OpenMetadata(suite, key, meta.Metadata)
 		if err != nil {
-			fmt.Printf("Failed to fetch metadata: %v\n", err)
+			fmt.Printf("Decryption failed on file metadata! The key is wrong or the metadata was tampered with: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("Found file: %s (Size: %d bytes, Chunks: %d)\n", meta.FileName, meta.FileSize, meta.ChunkCount)
+		// A single file is handled as a bundle of one entry covering every chunk
+		entries := manifest.Entries
+		if len(entries) == 0 {
+			entries = []crypto.BundleEntry{{
+				Name:       fileMeta.Name,
+				Size:       manifest.Size,
+				FileHash:   manifest.FileHash,
+				ChunkCount: manifest.ChunkCount,
+			}}
+		}
+		selected, err := selectEntries(entries, pullOnly)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
 
-		// 4. Create Output File
-		// We add "downloaded_" to the filename so we don't accidentally overwrite the original if testing locally
-		outputFileName := "downloaded_" + meta.FileName
-		outFile, err := os.Create(outputFileName)
+		// Check who produced the drop before writing anything to disk
+		senderStatus, err := checkSender(manifest, meta.Metadata)
 		if err != nil {
-			fmt.Printf("Failed to create output file: %v\n", err)
+			fmt.Printf("Sender verification failed: %v\n", err)
 			os.Exit(1)
 		}
-		defer outFile.Close()
+		fmt.Println(senderStatus)
 
-		// 5. Download and Decrypt Chunks
-		fmt.Println("Downloading and decrypting chunks...")
-		for i := 0; i < meta.ChunkCount; i++ {
-			fmt.Printf("   -> Pulling chunk %d/%d...\n", i+1, meta.ChunkCount)
-			
-			// Download
-			encryptedChunk, err := api.DownloadChunk(dropID, i)
-			if err != nil {
-				fmt.Printf("\nFailed to download chunk %d: %v\n", i, err)
-				os.Remove(outputFileName) // Clean up partial file
-				os.Exit(1)
+		if len(manifest.Entries) > 0 {
+			fmt.Printf("Found bundle: %d files (Size: %d bytes, Chunks: %d)\n", len(entries), manifest.Size, manifest.ChunkCount)
+			for _, entry := range entries {
+				fmt.Printf("   %s (%d bytes)\n", safeFileName(entry.Name, dropID), entry.Size)
 			}
+		} else {
+			fmt.Printf("Found file: %s (Size: %d bytes, Chunks: %d)\n", safeFileName(fileMeta.Name, dropID), manifest.Size, manifest.ChunkCount)
+		}
 
-			// Decrypt
-			plaintextChunk, err := crypto.Decrypt(key, encryptedChunk)
-			if err != nil {
-				fmt.Printf("\nDecryption failed on chunk %d! The data may be corrupted or the key is wrong: %v\n", i, err)
+		var saved []string
+		for _, entry := range selected {
+			// 5. Create Output File
+			// We add "downloaded_" to the filename so we don't accidentally overwrite the original if testing locally
+			outputFileName := "downloaded_" + safeFileName(entry.Name, dropID)
+			if len(manifest.Entries) > 0 {
+				fmt.Printf("Extracting %s\n", entry.Name)
+			}
+			if err := pullEntry(api, dropID, meta.DownloadToken, suite, key, manifest, entry, outputFileName); err != nil {
+				fmt.Printf("\nDownload failed: %v\n", err)
 				os.Remove(outputFileName) // Clean up partial file
+				removeFiles(saved)
 				os.Exit(1)
 			}
+			saved = append(saved, outputFileName)
+		}
 
-			// Write to disk
-			if _, err := outFile.Write(plaintextChunk); err != nil {
-				fmt.Printf("\nFailed to write to file: %v\n", err)
-				os.Remove(outputFileName)
-				os.Exit(1)
-			}
+		// 7. For convergent drops, check the key itself was derived from the content.
+		// Every entry pulled above matched its hash, and the manifest ties those to FileHash.
+		contentHash, _ := hex.DecodeString(manifest.FileHash)
+		if err := verifyConvergentKey(suite, manifest, key, contentHash); err != nil {
+			fmt.Printf("\nIntegrity check failed: %v\n", err)
+			removeFiles(saved)
+			os.Exit(1)
 		}
 
 		fmt.Println("\nDownload Complete!")
-		fmt.Printf("Saved as: %s\n", outputFileName)
+		for _, name := range saved {
+			fmt.Printf("Saved as: %s\n", name)
+		}
 	},
 }
 
+// pullEntry downloads, verifies and decrypts the chunks of one file in a drop
+// and writes it to outputFileName. token is the download token from the
+// metadata call.
+func pullEntry(api *client.APIClient, dropID, token string, suite *crypto.Suite, key []byte, manifest *crypto.Manifest, entry crypto.BundleEntry, outputFileName string) error {
+	outFile, err := os.Create(outputFileName)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outFile.Close()
+
+	// 6. Download, Verify and Decrypt Chunks
+	fmt.Println("Downloading and decrypting chunks...")
+	fileHasher := sha256.New()
+	var written int64
+	for i := entry.FirstChunk; i < entry.FirstChunk+entry.ChunkCount; i++ {
+		fmt.Printf("   -> Pulling chunk %d/%d...\n", i+1, manifest.ChunkCount)
+
+		// Download
+		encryptedChunk, err := api.DownloadChunk(dropID, token, i)
+		if err != nil {
+			return fmt.Errorf("failed to download chunk %d: %w", i, err)
+		}
+
+		// Make sure this is the exact chunk the sender uploaded at this position
+		if err := manifest.VerifyChunk(i, encryptedChunk); err != nil {
+			return fmt.Errorf("integrity check failed: %w", err)
+		}
+
+		// Decrypt
+		plaintextChunk, err := suite.Decrypt(key, encryptedChunk)
+		if err != nil {
+			return fmt.Errorf("decryption failed on chunk %d, the data may be corrupted or the key is wrong: %w", i, err)
+		}
+
+		// Padding lives past the true length recorded in the manifest; drop it
+		if remaining := entry.Size - written; int64(len(plaintextChunk)) > remaining {
+			plaintextChunk = plaintextChunk[:max(remaining, 0)]
+		}
+
+		// Write to disk
+		if _, err := outFile.Write(plaintextChunk); err != nil {
+			return fmt.Errorf("failed to write to file: %w", err)
+		}
+		fileHasher.Write(plaintextChunk)
+		written += int64(len(plaintextChunk))
+	}
+
+	// Verify the whole file against the manifest
+	if written != entry.Size || hex.EncodeToString(fileHasher.Sum(nil)) != entry.FileHash {
+		return errors.New("integrity check failed: the decrypted file does not match the manifest")
+	}
+	return nil
+}
+
+// selectEntries picks the bundle entries named with --only, or all of them.
+func selectEntries(entries []crypto.BundleEntry, only []string) ([]crypto.BundleEntry, error) {
+	if len(only) == 0 {
+		return entries, nil
+	}
+	var selected []crypto.BundleEntry
+	for _, name := range only {
+		found := false
+		for _, entry := range entries {
+			if entry.Name == name {
+				selected = append(selected, entry)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("the drop has no file named %q", name)
+		}
+	}
+	return selected, nil
+}
+
+func removeFiles(names []string) {
+	for _, name := range names {
+		os.Remove(name)
+	}
+}
+
+// verifyConvergentKey checks that a content-derived key really was derived from
+// the downloaded file. Random (private) keys have nothing to check, and team keys
+// can only be checked by members holding the same team secret.
+func verifyConvergentKey(suite *crypto.Suite, manifest *crypto.Manifest, key, fileHash []byte) error {
+	switch suite.KeyMode {
+	case crypto.KeyConvergent:
+		if !bytes.Equal(fileHash, key) {
+			return fmt.Errorf("the file's SHA-256 does not match the convergent key")
+		}
+	case crypto.KeyTeam:
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		teamSecret, err := cfg.teamSecret()
+		if err != nil {
+			return err
+		}
+		if teamSecret == nil || crypto.TeamKeyID(teamSecret) != manifest.KeyID {
+			fmt.Printf("Note: team-keyed drop (key ID %s); skipping key derivation check, no matching team secret configured.\n", manifest.KeyID)
+			return nil
+		}
+		expected, err := crypto.DeriveTeamKey(teamSecret, fileHash)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(expected, key) {
+			return fmt.Errorf("the key was not derived from this file with your team secret")
+		}
+	}
+	return nil
+}
+
+// checkSender verifies the sender signature against the trusted senders file.
+// A bad signature is always fatal; unsigned drops and unknown signers only
+// produce a warning unless --require-signed is set. The returned line
+// describes the sender for the user.
+func checkSender(manifest *crypto.Manifest, sealedMetadata []byte) (string, error) {
+	signer, err := manifest.VerifySignature(sealedMetadata)
+	if errors.Is(err, crypto.ErrUnsigned) {
+		if requireSigned {
+			return "", fmt.Errorf("the drop is not signed (--require-signed)")
+		}
+		return "WARNING: This drop is not signed. Anyone could have pushed it.", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+	trusted, err := loadTrustedSenders(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	fingerprint := ssh.FingerprintSHA256(signer)
+	if name, ok := trusted[fingerprint]; ok {
+		return fmt.Sprintf("Signed by   : %s (%s)", name, fingerprint), nil
+	}
+	if requireSigned {
+		return "", fmt.Errorf("the drop is signed by %s, which is not in your trusted senders", fingerprint)
+	}
+	return fmt.Sprintf("WARNING: Signed by an unknown key %s. Add it to your trusted senders if you expected it.", fingerprint), nil
+}
+
+// precheckSender runs checkSender against the non-consuming info endpoint.
+func precheckSender(api *client.APIClient, dropID string, key []byte) error {
+	manifest, info, err := precheckManifest(api, dropID, key)
+	if err != nil {
+		return err
+	}
+	_, err = checkSender(manifest, info.Metadata)
+	return err
+}
+
+// precheckOnly checks the --only names against the manifest from the
+// non-consuming info endpoint.
+func precheckOnly(api *client.APIClient, dropID string, key []byte) error {
+	manifest, info, err := precheckManifest(api, dropID, key)
+	if err != nil {
+		return err
+	}
+	if len(manifest.Entries) > 0 {
+		_, err = selectEntries(manifest.Entries, pullOnly)
+		return err
+	}
+	suite, _ := crypto.LookupSuite(info.Algorithm)
+	fileMeta, err := crypto.OpenMetadata(suite, key, info.Metadata)
+	if err != nil {
+		return err
+	}
+	_, err = selectEntries([]crypto.BundleEntry{{Name: fileMeta.Name}}, pullOnly)
+	return err
+}
+
+// precheckManifest opens the manifest served by the info endpoint.
+func precheckManifest(api *client.APIClient, dropID string, key []byte) (*crypto.Manifest, *client.DropInfoResponse, error) {
+	info, err := api.GetDropInfo(dropID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(info.Manifest) == 0 {
+		return nil, nil, fmt.Errorf("the drop has no manifest")
+	}
+	suite, err := crypto.LookupSuite(info.Algorithm)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest, err := crypto.OpenManifest(suite, key, info.Manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+	return manifest, info, nil
+}
+
 func init() {
 	rootCmd.AddCommand(pullCmd)
+	pullCmd.Flags().BoolVar(&requireSigned, "require-signed", false, "Refuse drops that are unsigned or signed by someone not in your trusted senders")
+	pullCmd.Flags().StringArrayVarP(&identityFiles, "identity", "i", nil, "Identity file for drops encrypted with push --to (repeatable)")
+	pullCmd.Flags().BoolVar(&pullWait, "wait", false, "Wait for an embargoed drop to open instead of failing")
+	pullCmd.Flags().StringArrayVar(&pullOnly, "only", nil, "Extract only this file from a bundle (repeatable)")
+	pullCmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "Read the passphrase for a protected drop from this file")
 }
\ No newline at end of file
@@ -3,138 +3,709 @@ package cli
 import (
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/sumanthd032/codedrop/internal/client"
 	"github.com/sumanthd032/codedrop/internal/crypto"
+	"golang.org/x/crypto/ssh"
 )
 
 var (
-	expire   string
-	maxViews int
+	expire    string
+	maxViews  int
+	padScheme string
+	private   bool
+	noTeam    bool
+
+	usePassphrase bool
+	recipientArgs []string
+	signKeyPath   string
+	splitSpec     string
+	cipherName    string
+	useShortCode  bool
+	intoURL       string
+	grantLabels   []string
+	notBeforeArg  string
+	idleTTL       string
+	gracePeriod   string
 )
 
+// chunkSize is the plaintext size of each uploaded chunk; only the last is shorter.
+const chunkSize = 4 * 1024 * 1024 // 4MB chunks
+
 var pushCmd = &cobra.Command{
-	Use:   "push [file_path]",
+	Use:   "push [file_path...]",
 	Short: "Encrypt and push a file to the CodeDrop server",
-	Args:  cobra.ExactArgs(1),
+	Long: `Encrypts a file, uploads it and prints a share URL. Several files are
+pushed as one bundle drop: pull extracts all of them, or some with --only.`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		filePath := args[0]
 		serverURL, _ := cmd.Flags().GetString("server")
 
-		// 1. Open the file
-		file, err := os.Open(filePath)
+		// 1. Open and hash the files
+		files, err := openPushFiles(args)
 		if err != nil {
-			fmt.Printf("Error opening file: %v\n", err)
+			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
-		defer file.Close()
+		for _, f := range files {
+			defer f.Close()
+		}
+		bundle := len(files) > 1
+
+		// 2. The content hash always goes into the manifest, and in the default
+		// convergent mode it is also the key. A bundle's covers every entry.
+		var entries []crypto.BundleEntry
+		var totalSize int64
+		for _, f := range files {
+			entries = append(entries, crypto.BundleEntry{
+				Name:     filepath.Base(f.info.Name()),
+				MimeType: detectMimeType(f.File),
+				Size:     f.info.Size(),
+				ModTime:  f.info.ModTime().UTC(),
+				FileHash: hex.EncodeToString(f.hash),
+			})
+			totalSize += f.info.Size()
+		}
+		fileHash := files[0].hash
+		if bundle {
+			fileHash, err = crypto.BundleHash(entries)
+			if err != nil {
+				fmt.Printf("Error hashing bundle: %v\n", err)
+				os.Exit(1)
+			}
+		}
 
-		fileInfo, err := file.Stat()
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		teamSecret, err := cfg.teamSecret()
 		if err != nil {
-			fmt.Printf("Error reading file info: %v\n", err)
+			fmt.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
-		if fileInfo.IsDir() {
-			fmt.Println("Error: CodeDrop currently only supports single files, not directories. Zip it first!")
+		// Pick the key mode from the flags, then derive the drop key
+		keyMode := pushKeyMode(teamSecret)
+		switch keyMode {
+		case crypto.KeyPrivate:
+			// Random key and nonces: no dedup, but nobody can confirm a guessed file
+			fmt.Println("Generating random encryption key (private mode, no deduplication)...")
+		case crypto.KeyTeam:
+			// Team-keyed convergent key: dedupes across the team, opaque to everyone else
+			fmt.Println("Generating team-keyed convergent encryption key (CAS compatible)...")
+		default:
+			fmt.Println("Generating convergent encryption key (CAS compatible)...")
+		}
+		key, err := newDropKey(keyMode, fileHash, teamSecret)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
+		encodedKey := base64.URLEncoding.EncodeToString(key)
+		var keyID string
+		if keyMode == crypto.KeyTeam {
+			keyID = crypto.TeamKeyID(teamSecret)
+		}
 
-		// 2. Generate Convergent Encryption Key (CAS Compatible)
-		fmt.Println("Generating convergent encryption key (CAS compatible)...")
-		
-		// We hash the entire file to create a deterministic 32-byte (256-bit) key
-		hasher := sha256.New()
-		fileForHash, err := os.Open(filePath)
+		// The cipher suite is recorded with the drop, so pull knows how to open it
+		suite, err := crypto.SelectSuite(cipherName, keyMode)
 		if err != nil {
-			fmt.Printf("Error opening file for hashing: %v\n", err)
+			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
-		if _, err := io.Copy(hasher, fileForHash); err != nil {
-			fmt.Printf("Error hashing file: %v\n", err)
+		algorithm := suite.ID
+
+		// --split M-of-N replaces the key in the URL with N key shares
+		var threshold, shareCount int
+		if splitSpec != "" {
+			if _, err := fmt.Sscanf(splitSpec, "%d-of-%d", &threshold, &shareCount); err != nil {
+				fmt.Printf("Error: invalid --split %q, expected e.g. 2-of-3\n", splitSpec)
+				os.Exit(1)
+			}
+			if len(recipientArgs) > 0 || usePassphrase || passphraseFile != "" {
+				fmt.Println("Error: --split cannot be combined with --to or --passphrase")
+				os.Exit(1)
+			}
+			if useShortCode {
+				fmt.Println("Error: --split cannot be combined with --code")
+				os.Exit(1)
+			}
+		}
+
+		// --recipients gives each person their own link, download limit and revocation
+		var grants []client.GrantRequest
+		var grantKeys [][]byte
+		if len(grantLabels) > 0 {
+			if len(recipientArgs) > 0 || usePassphrase || passphraseFile != "" || splitSpec != "" || useShortCode || intoURL != "" {
+				fmt.Println("Error: --recipients cannot be combined with --to, --passphrase, --split, --code or --into")
+				os.Exit(1)
+			}
+			grants, grantKeys, err = newGrants(grantLabels, key)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if intoURL != "" && (idleTTL != "" || gracePeriod != "") {
+			fmt.Println("Error: --idle-ttl and --grace cannot be combined with --into; the requester sets the expiry")
 			os.Exit(1)
 		}
-		fileForHash.Close()
 
-		key := hasher.Sum(nil) // This is exactly 32 bytes, perfect for AES-256
-		encodedKey := base64.URLEncoding.EncodeToString(key)
+		// --not-before embargoes the drop: the server refuses downloads until then
+		var notBefore *time.Time
+		if notBeforeArg != "" {
+			if intoURL != "" {
+				fmt.Println("Error: --not-before cannot be combined with --into")
+				os.Exit(1)
+			}
+			at, err := parseNotBefore(notBeforeArg, time.Now())
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if d, err := time.ParseDuration(expire); err == nil && !at.Before(time.Now().Add(d)) {
+				fmt.Printf("Error: --not-before must be earlier than the drop's expiry (--expire %s from now)\n", expire)
+				os.Exit(1)
+			}
+			notBefore = &at
+		}
+
+		// Load the signing key up front so a locked key or missing agent fails early
+		var signer ssh.Signer
+		if signKeyPath == "" {
+			signKeyPath = cfg.SigningKey
+		}
+		if signKeyPath != "" {
+			signer, err = loadSigner(signKeyPath)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// --into fills a slot made with 'codedrop request': the key is wrapped to the requester
+		var intoDropID string
+		if intoURL != "" {
+			if len(recipientArgs) > 0 || usePassphrase || passphraseFile != "" || splitSpec != "" || useShortCode {
+				fmt.Println("Error: --into cannot be combined with --to, --passphrase, --split or --code")
+				os.Exit(1)
+			}
+			if cmd.Flags().Changed("expire") || cmd.Flags().Changed("max-views") {
+				fmt.Println("Error: expiry and max views are set by the requester")
+				os.Exit(1)
+			}
+			var pin string
+			serverURL, intoDropID, pin, err = parseRequestURL(intoURL)
+			if err != nil {
+				fmt.Printf("Invalid URL: %v\n", err)
+				os.Exit(1)
+			}
+			slot, err := client.NewAPIClient(serverURL).GetRequest(intoDropID)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if requestPin(slot.Recipient) != pin {
+				fmt.Println("Error: the server's public key for this request does not match the URL. Refusing to upload.")
+				os.Exit(1)
+			}
+			recipientArgs = []string{slot.Recipient}
+			maxViews = slot.MaxDownloads
+		}
+
+		// With --to the key is wrapped to each recipient and left out of the URL
+		var recipients []crypto.Recipient
+		toRecipients := len(recipientArgs) > 0
+		if toRecipients {
+			if usePassphrase || passphraseFile != "" {
+				fmt.Println("Error: --to and --passphrase cannot be combined")
+				os.Exit(1)
+			}
+			recipients, err = parseRecipients(recipientArgs)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if intoDropID != "" {
+				fmt.Printf("Wrapping key to the requester (%s)...\n", recipients[0])
+			} else {
+				fmt.Printf("Wrapping key to %d recipient(s)...\n", len(recipients))
+			}
+		}
+
+		// An organization escrow key gets a copy too, so a lost URL can be recovered
+		escrowed := cfg.EscrowRecipient != ""
+		if escrowed {
+			fmt.Println("Escrowing key to the organization recovery key...")
+		}
+		wrapped, err := wrapStanzas(key, recipients, cfg.EscrowRecipient)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var stanzas []byte
+		if len(wrapped) > 0 {
+			stanzas, err = crypto.MarshalStanzas(wrapped)
+			if err != nil {
+				fmt.Printf("Error encoding wrapped keys: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// With --passphrase the URL only carries a wrapped key. Ask before contacting
+		// the server so a typo doesn't leave a half-created drop behind.
+		var kdfParams *crypto.KDFParams
+		var wrappingKey []byte
+		if usePassphrase || passphraseFile != "" {
+			passphrase, err := readPassphrase(true)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			kdfParams, err = crypto.NewKDFParams()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("Deriving wrapping key from passphrase (Argon2id)...")
+			wrappingKey = kdfParams.DeriveWrappingKey(passphrase)
+		}
 
 		// 3. Initialize API Client and Create Drop
 		fmt.Println("Contacting CodeDrop Server...")
 		api := client.NewAPIClient(serverURL)
 		
+		// File name, type and timestamps are encrypted so the server only sees an opaque blob.
+		// A bundle's entries are listed in the manifest instead.
+		fileMeta := &crypto.FileMetadata{
+			Name:     entries[0].Name,
+			MimeType: entries[0].MimeType,
+			Size:     totalSize,
+			ModTime:  entries[0].ModTime,
+		}
+		if bundle {
+			fileMeta = &crypto.FileMetadata{
+				Name:     fmt.Sprintf("%d files", len(entries)),
+				MimeType: crypto.BundleMimeType,
+				Size:     totalSize,
+			}
+		}
+		sealedMeta, err := crypto.SealMetadata(suite, key, fileMeta)
+		if err != nil {
+			fmt.Printf("Error encrypting file metadata: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Padding hides the exact size; the server only ever learns the padded length
+		paddedSize, err := crypto.PaddedSize(padScheme, totalSize)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
 		dropReq := client.CreateDropRequest{
-			FileName:       filepath.Base(fileInfo.Name()),
-			FileSize:       fileInfo.Size(),
-			EncryptionSalt: "v1-aes-gcm", // Future-proofing in case we change algorithms
-			ExpiresIn:      expire,
-			MaxDownloads:   maxViews,
+			Metadata:     sealedMeta,
+			FileSize:     paddedSize,
+			Algorithm:    algorithm,
+			KeyID:        keyID,
+			WrappedKeys:  stanzas,
+			ExpiresIn:    expire,
+			MaxDownloads: maxViews,
+			Grants:       grants,
+			NotBefore:    notBefore,
+			IdleTTL:      idleTTL,
+			GracePeriod:  gracePeriod,
+		}
+
+		if kdfParams != nil {
+			dropReq.EncryptionSalt = kdfParams.String()
 		}
 
-		dropResp, err := api.CreateDrop(dropReq)
+		var dropResp *client.CreateDropResponse
+		if intoDropID != "" {
+			dropResp, err = api.FillRequest(intoDropID, client.FillRequestRequest{
+				Metadata:       dropReq.Metadata,
+				FileSize:       dropReq.FileSize,
+				EncryptionSalt: dropReq.EncryptionSalt,
+				Algorithm:      dropReq.Algorithm,
+				KeyID:          dropReq.KeyID,
+				WrappedKeys:    dropReq.WrappedKeys,
+			})
+		} else {
+			dropResp, err = api.CreateDrop(dropReq)
+		}
 		if err != nil {
 			fmt.Printf("Error creating drop: %v\n", err)
 			os.Exit(1)
 		}
+		// The server only accepts chunks and the manifest from whoever created the drop
+		uploadToken := dropResp.OwnerToken
+		if intoDropID != "" {
+			uploadToken = dropResp.UploadToken
+		}
 
 		// 4. Chunk, Encrypt, and Upload
-		const chunkSize = 4 * 1024 * 1024 // 4MB chunks
 		buffer := make([]byte, chunkSize)
 		chunkIndex := 0
 
-		fmt.Printf("Uploading %s (Size: %d bytes)\n", dropReq.FileName, fileInfo.Size())
+		// The manifest records every encrypted chunk so pull can detect truncation or reordering
+		manifest := &crypto.Manifest{
+			Version:   crypto.ManifestVersion,
+			Algorithm: algorithm,
+			KeyID:     keyID,
+			Size:      totalSize,
+			Padding:   padScheme,
+			FileHash:  hex.EncodeToString(fileHash),
+		}
 
-		for {
-			// Read a chunk from the file
-			bytesRead, err := file.Read(buffer)
-			if err != nil && err != io.EOF {
-				fmt.Printf("Error reading file: %v\n", err)
-				os.Exit(1)
+		if bundle {
+			fmt.Printf("Uploading bundle of %d files (Size: %d bytes)\n", len(entries), totalSize)
+		} else {
+			fmt.Printf("Uploading %s (Size: %d bytes)\n", fileMeta.Name, totalSize)
+		}
+		if paddedSize != totalSize {
+			fmt.Printf("Padding to %d bytes (%s)\n", paddedSize, padScheme)
+		}
+
+		// Each file starts on a fresh chunk, so pull can fetch a bundle entry on its own
+		for i, f := range files {
+			// Padding bytes are appended to the last stream, so they land in the final chunk(s)
+			var stream io.Reader = f
+			if i == len(files)-1 {
+				stream = crypto.PadReader(f, f.info.Size(), f.info.Size()+paddedSize-totalSize)
 			}
-			if bytesRead == 0 {
-				break // End of file
+			if bundle {
+				fmt.Printf(" %s (%d bytes)\n", entries[i].Name, entries[i].Size)
 			}
+			entries[i].FirstChunk = chunkIndex
+
+			for {
+				// Read a chunk from the file
+				bytesRead, err := io.ReadFull(stream, buffer)
+				if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+					fmt.Printf("Error reading file: %v\n", err)
+					os.Exit(1)
+				}
+				if bytesRead == 0 {
+					break // End of file
+				}
+
+				// Encrypt the chunk
+				plaintextChunk := buffer[:bytesRead]
+				ciphertext, err := suite.Encrypt(key, plaintextChunk)
+				if err != nil {
+					fmt.Printf("Error encrypting chunk %d: %v\n", chunkIndex, err)
+					os.Exit(1)
+				}
 
-			// Encrypt the chunk
-			plaintextChunk := buffer[:bytesRead]
-			ciphertext, err := crypto.Encrypt(key, plaintextChunk)
+				// Upload the encrypted chunk
+				fmt.Printf("   -> Pushing chunk %d...\n", chunkIndex)
+				err = api.UploadChunk(dropResp.DropID, uploadToken, chunkIndex, ciphertext)
+				if err != nil {
+					fmt.Printf("Error uploading chunk %d: %v\n", chunkIndex, err)
+					os.Exit(1)
+				}
+
+				chunkHash := sha256.Sum256(ciphertext)
+				manifest.ChunkHashes = append(manifest.ChunkHashes, hex.EncodeToString(chunkHash[:]))
+				chunkIndex++
+			}
+			entries[i].ChunkCount = chunkIndex - entries[i].FirstChunk
+		}
+		if bundle {
+			manifest.Version = crypto.BundleManifestVersion
+			manifest.Entries = entries
+		}
+
+		// 5. Sign and seal the drop with the encrypted manifest
+		manifest.ChunkCount = chunkIndex
+		if signer != nil {
+			err := crypto.SignManifest(manifest, sealedMeta, signer)
+			closeSigner(signer)
 			if err != nil {
-				fmt.Printf("Error encrypting chunk %d: %v\n", chunkIndex, err)
+				fmt.Printf("Error signing manifest: %v\n", err)
 				os.Exit(1)
 			}
+			fmt.Printf("Signed by %s\n", ssh.FingerprintSHA256(signer.PublicKey()))
+		}
+		sealedManifest, err := crypto.SealManifest(suite, key, manifest)
+		if err != nil {
+			fmt.Printf("Error encrypting manifest: %v\n", err)
+			os.Exit(1)
+		}
+		if err := api.UploadManifest(dropResp.DropID, uploadToken, sealedManifest); err != nil {
+			fmt.Printf("Error uploading manifest: %v\n", err)
+			os.Exit(1)
+		}
+
+		// The owner token is only needed later, to reshare the drop
+		if err := saveOwnerToken(serverURL, dropResp); err != nil {
+			fmt.Printf("Warning: could not save the owner token (%v). To reshare later, pass --owner-token %s\n", err, dropResp.OwnerToken)
+		}
 
-			// Upload the encrypted chunk
-			fmt.Printf("   -> Pushing chunk %d...\n", chunkIndex)
-			err = api.UploadChunk(dropResp.DropID, chunkIndex, ciphertext)
+		// 6. Generate Output URL
+		// The fragment (#) ensures the browser/CLI doesn't send the key to the server during the GET request.
+		fragment := "k=" + encodedKey
+		if wrappingKey != nil {
+			wrapped, err := crypto.WrapKey(wrappingKey, key, dropResp.DropID)
 			if err != nil {
-				fmt.Printf("Error uploading chunk %d: %v\n", chunkIndex, err)
+				fmt.Printf("Error wrapping key: %v\n", err)
 				os.Exit(1)
 			}
+			fragment = "w=" + base64.URLEncoding.EncodeToString(wrapped)
+		}
+		finalURL := fmt.Sprintf("%s/drop/%s#%s", serverURL, dropResp.DropID, fragment)
+		if toRecipients {
+			finalURL = fmt.Sprintf("%s/drop/%s", serverURL, dropResp.DropID)
+		}
 
-			chunkIndex++
+		// A short code stands in for the URL; the fragment is sealed under its words
+		var shortCode string
+		if useShortCode {
+			codeFragment := fragment
+			if toRecipients {
+				codeFragment = ""
+			}
+			fmt.Println("Registering short code...")
+			shortCode, err = createShortCode(api, dropResp.DropID, dropResp.OwnerToken, codeFragment)
+			if err != nil {
+				fmt.Printf("Error creating short code: %v\n", err)
+				os.Exit(1)
+			}
 		}
 
-		// 5. Generate Output URL
-		// The fragment (#) ensures the browser/CLI doesn't send the key to the server during the GET request.
-		finalURL := fmt.Sprintf("%s/drop/%s#k=%s", serverURL, dropResp.DropID, encodedKey)
+		var shareURLs []string
+		if splitSpec != "" {
+			shares, err := crypto.SplitKey(key, threshold, shareCount)
+			if err != nil {
+				fmt.Printf("Error splitting key: %v\n", err)
+				os.Exit(1)
+			}
+			for _, share := range shares {
+				shareURLs = append(shareURLs, fmt.Sprintf("%s/drop/%s#s=%s", serverURL, dropResp.DropID, base64.URLEncoding.EncodeToString(share)))
+			}
+		}
 
 		fmt.Println("\nUpload Complete!")
 		fmt.Println("--------------------------------------------------")
-		fmt.Printf("Secure URL : %s\n", finalURL)
+		if shareURLs != nil {
+			for i, shareURL := range shareURLs {
+				fmt.Printf("Share %d/%d  : %s\n", i+1, shareCount, shareURL)
+			}
+		} else if grants != nil {
+			for i, g := range grants {
+				fmt.Printf("%-10s : %s/drop/%s#g=%s\n", g.Label, serverURL, dropResp.DropID, base64.URLEncoding.EncodeToString(grantKeys[i]))
+			}
+		} else {
+			fmt.Printf("Secure URL : %s\n", finalURL)
+		}
+		if shortCode != "" {
+			fmt.Printf("Short Code : %s\n", shortCode)
+		}
+		if notBefore != nil {
+			fmt.Printf("Opens At   : %s\n", notBefore.Local().Format("Jan 02, 2006 15:04:05 MST"))
+		}
 		fmt.Printf("Expires At : %s\n", dropResp.ExpiresAt.Local().Format("Jan 02, 2006 15:04:05 MST"))
-		fmt.Printf("Max Views  : %d\n", maxViews)
+		if idleTTL != "" {
+			fmt.Printf("Idle TTL   : deleted after %s without a download\n", idleTTL)
+		}
+		if gracePeriod != "" {
+			fmt.Printf("Grace      : deleted %s after the first download\n", gracePeriod)
+		}
+		if grants != nil {
+			fmt.Printf("Max Views  : %d per recipient\n", maxViews)
+		} else {
+			fmt.Printf("Max Views  : %d\n", maxViews)
+		}
 		fmt.Println("--------------------------------------------------")
-		fmt.Println("WARNING: Anyone with this URL can decrypt the file. Do not lose it; the key cannot be recovered.")
+		if grants != nil {
+			fmt.Println("Give each person only their own URL. Each has its own download limit.")
+			fmt.Println("See who downloaded with 'codedrop status'; revoke a link with 'codedrop revoke <url> <name>'.")
+		} else if shareURLs != nil {
+			fmt.Printf("Any %d of these URLs are needed to decrypt the file. Give each share to a different person.\n", threshold)
+		} else if intoDropID != "" {
+			fmt.Println("Delivered to the requester. Only their identity can decrypt the file.")
+		} else if toRecipients {
+			fmt.Println("Only the listed recipients can decrypt the file; the URL carries no key.")
+		} else if wrappingKey != nil {
+			fmt.Println("The URL alone cannot decrypt the file. Share the passphrase over a different channel.")
+		} else if escrowed {
+			fmt.Println("WARNING: Anyone with this URL can decrypt the file. If it is lost, an admin can recover the key from escrow.")
+		} else {
+			fmt.Println("WARNING: Anyone with this URL can decrypt the file. Do not lose it; the key cannot be recovered.")
+		}
+		if shortCode != "" {
+			fmt.Printf("Receive with: codedrop pull %s (the server allows only a few attempts per code)\n", shortCode)
+		}
 	},
 }
 
+// pushKeyMode picks how push makes the drop key. A key wrapped to recipients or
+// to a passphrase, handed out per grant, split into shares or sent into a
+// request must not be derivable from the file: anyone who hashes the file could
+// open the drop, and an older #k= URL for the same file would too.
+func pushKeyMode(teamSecret []byte) string {
+	if private || usePassphrase || passphraseFile != "" || len(recipientArgs) > 0 || len(grantLabels) > 0 || splitSpec != "" || intoURL != "" {
+		return crypto.KeyPrivate
+	}
+	if teamSecret != nil && !noTeam {
+		return crypto.KeyTeam
+	}
+	return crypto.KeyConvergent
+}
+
+// newDropKey makes the drop key for a key mode: random, derived from the team
+// secret, or the file hash itself (exactly 32 bytes, perfect for AES-256).
+func newDropKey(keyMode string, fileHash, teamSecret []byte) ([]byte, error) {
+	switch keyMode {
+	case crypto.KeyPrivate:
+		key, _, err := crypto.GenerateKey()
+		return key, err
+	case crypto.KeyTeam:
+		key, err := crypto.DeriveTeamKey(teamSecret, fileHash)
+		if err != nil {
+			return nil, fmt.Errorf("deriving team key: %w", err)
+		}
+		return key, nil
+	default:
+		return fileHash, nil
+	}
+}
+
+// newGrants creates one access grant per name: a random grant key for the
+// person's URL, and the drop key wrapped under it for the server.
+func newGrants(labels []string, key []byte) ([]client.GrantRequest, [][]byte, error) {
+	var grants []client.GrantRequest
+	var grantKeys [][]byte
+	seen := make(map[string]bool)
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" || seen[label] {
+			return nil, nil, fmt.Errorf("--recipients names must be non-empty and unique")
+		}
+		seen[label] = true
+
+		grantKey, err := crypto.NewGrantKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		wrapped, err := crypto.WrapGrantKey(grantKey, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		grants = append(grants, client.GrantRequest{ID: crypto.GrantID(grantKey), Label: label, WrappedKey: wrapped})
+		grantKeys = append(grantKeys, grantKey)
+	}
+	return grants, grantKeys, nil
+}
+
+// parseNotBefore reads a --not-before value: a time such as
+// 2026-03-01T09:00:00Z or 2026-03-01 09:00 (local time), or a delay from now
+// such as 2h30m.
+func parseNotBefore(value string, now time.Time) (time.Time, error) {
+	var at time.Time
+	if d, err := time.ParseDuration(value); err == nil {
+		at = now.Add(d)
+	} else if t, err := time.Parse(time.RFC3339, value); err == nil {
+		at = t
+	} else if t, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local); err == nil {
+		at = t
+	} else if t, err := time.ParseInLocation("2006-01-02T15:04", value, time.Local); err == nil {
+		at = t
+	} else {
+		return time.Time{}, fmt.Errorf("invalid --not-before %q (use e.g. 2h, 2026-03-01 09:00 or 2026-03-01T09:00:00Z)", value)
+	}
+	if !at.After(now) {
+		return time.Time{}, fmt.Errorf("--not-before %q is not in the future", value)
+	}
+	return at, nil
+}
+
+// pushFile is an open file being pushed, with the SHA-256 of its contents.
+type pushFile struct {
+	*os.File
+	info os.FileInfo
+	hash []byte
+}
+
+// openPushFiles opens and hashes every file to push. Bundle entries are named
+// after the files' base names, so those must be unique.
+func openPushFiles(paths []string) ([]*pushFile, error) {
+	var files []*pushFile
+	names := make(map[string]bool)
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening file: %w", err)
+		}
+		info, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("reading file info: %w", err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory. Zip it first, or list the files to push them as a bundle", path)
+		}
+		if names[info.Name()] {
+			return nil, fmt.Errorf("two files are named %s; bundle entries need unique names", info.Name())
+		}
+		names[info.Name()] = true
+
+		hasher := sha256.New()
+		if _, err := io.Copy(hasher, file); err != nil {
+			return nil, fmt.Errorf("hashing %s: %w", path, err)
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		files = append(files, &pushFile{File: file, info: info, hash: hasher.Sum(nil)})
+	}
+	return files, nil
+}
+
+// detectMimeType guesses the content type from the extension, falling back to sniffing
+// the first bytes of the file. The file offset is reset before returning.
+func detectMimeType(file *os.File) string {
+	if mimeType := mime.TypeByExtension(filepath.Ext(file.Name())); mimeType != "" {
+		return mimeType
+	}
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	file.Seek(0, io.SeekStart)
+	return http.DetectContentType(head[:n])
+}
+
 func init() {
 	rootCmd.AddCommand(pushCmd)
 	pushCmd.Flags().StringVarP(&expire, "expire", "e", "24h", "Time until the drop is permanently deleted (e.g., 30m, 24h)")
 	pushCmd.Flags().IntVarP(&maxViews, "max-views", "m", 1, "Maximum number of times this drop can be downloaded")
+	pushCmd.Flags().BoolVar(&private, "private", false, "Use a random key instead of a convergent one (disables deduplication)")
+	pushCmd.Flags().BoolVar(&noTeam, "no-team", false, "Ignore the configured team secret and use a plain convergent key")
+	pushCmd.Flags().BoolVar(&usePassphrase, "passphrase", false, "Also require a passphrase (Argon2id) to decrypt; the URL alone is not enough")
+	pushCmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "Read the passphrase from this file instead of prompting")
+	pushCmd.Flags().StringArrayVarP(&recipientArgs, "to", "t", nil, "Encrypt to a recipient (age1... or SSH ed25519 public key, or a file of them); repeatable")
+	pushCmd.Flags().StringVar(&signKeyPath, "sign", "", "Sign the drop with this Ed25519 SSH key (a .pub file signs via ssh-agent)")
+	pushCmd.Flags().StringVar(&splitSpec, "split", "", "Split the key into shares, e.g. 2-of-3 prints 3 URLs of which any 2 decrypt")
+	pushCmd.Flags().StringVar(&cipherName, "cipher", crypto.DefaultCipher, "Cipher for the drop (aes-256-gcm, xchacha20-poly1305)")
+	pushCmd.Flags().BoolVar(&useShortCode, "code", false, "Also print a short code (e.g. 7-crossbow-lantern-amber-anvil-bread) to read out instead of the URL")
+	pushCmd.Flags().StringVar(&intoURL, "into", "", "Upload into a request URL from 'codedrop request', encrypted to the requester")
+	pushCmd.Flags().StringSliceVar(&grantLabels, "recipients", nil, "Give each named person their own link and download limit, e.g. alice,bob,carol")
+	pushCmd.Flags().StringVar(&notBeforeArg, "not-before", "", "Embargo the drop until this time (e.g. 2h, \"2026-03-01 09:00\", 2026-03-01T09:00:00Z)")
+	pushCmd.Flags().StringVar(&idleTTL, "idle-ttl", "", "Also delete the drop after this long without a download (e.g., 30m)")
+	pushCmd.Flags().StringVar(&gracePeriod, "grace", "", "Also delete the drop this long after its first completed download (e.g., 10m)")
+	pushCmd.Flags().StringVar(&padScheme, "pad", crypto.PadNone, "Pad the upload to hide its exact size (none, padme, pow2)")
 }
\ No newline at end of file
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+func TestPushKeyNotDerivableFromFile(t *testing.T) {
+	content := []byte("Push Key Test")
+	fileHash := sha256.Sum256(content) // The convergent key
+	teamSecret := bytes.Repeat([]byte{7}, crypto.MinTeamSecretSize)
+
+	tests := []struct {
+		name string
+		set  func()
+	}{
+		{"passphrase", func() { usePassphrase = true }},
+		{"passphrase file", func() { passphraseFile = "passphrase.txt" }},
+		{"grants", func() { grantLabels = []string{"alice", "bob"} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usePassphrase, passphraseFile, grantLabels = false, "", nil
+			t.Cleanup(func() { usePassphrase, passphraseFile, grantLabels = false, "", nil })
+			tt.set()
+
+			// A team secret in the config must not turn it back into a file-derived key
+			keyMode := pushKeyMode(teamSecret)
+			if keyMode != crypto.KeyPrivate {
+				t.Fatalf("Expected key mode %q, got %q", crypto.KeyPrivate, keyMode)
+			}
+			key, err := newDropKey(keyMode, fileHash[:], teamSecret)
+			if err != nil {
+				t.Fatalf("Making the drop key failed: %v", err)
+			}
+			if bytes.Equal(key, fileHash[:]) {
+				t.Error("Drop key equals the convergent key of the file")
+			}
+		})
+	}
+
+	// Without any of these flags the key stays convergent, so dedup keeps working
+	usePassphrase, passphraseFile, grantLabels = false, "", nil
+	if keyMode := pushKeyMode(nil); keyMode != crypto.KeyConvergent {
+		t.Errorf("Expected key mode %q for a plain push, got %q", crypto.KeyConvergent, keyMode)
+	}
+}
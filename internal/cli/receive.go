@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spf13/cobra"
+	"github.com/sumanthd032/codedrop/internal/client"
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+var receiveCmd = &cobra.Command{
+	Use:   "receive [url]",
+	Short: "Receive a file streamed live with 'codedrop send'",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// 1. Parse the relay URL: http://host/relay/<id>#k=<key>
+		baseURL, relayID, key, err := parseRelayURL(args[0])
+		if err != nil {
+			fmt.Printf("Invalid URL: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 2. Join the relay and read the sender's header
+		fmt.Println("Joining relay...")
+		conn, err := client.NewAPIClient(baseURL).DialRelayReceive(relayID)
+		if err != nil {
+			fmt.Printf("Failed to join relay: %v\n", err)
+			os.Exit(1)
+		}
+		defer conn.Close()
+
+		var header client.RelayMessage
+		if err := conn.ReadJSON(&header); err != nil || header.Type != "header" {
+			fmt.Printf("Relay closed before the transfer started: %v\n", relayCloseReason(err))
+			os.Exit(1)
+		}
+		suite, err := crypto.LookupSuite(header.Algorithm)
+		if err != nil {
+			fmt.Printf("%v. Try upgrading codedrop.\n", err)
+			os.Exit(1)
+		}
+		fileMeta, err := crypto.OpenMetadata(suite, key, header.Metadata)
+		if err != nil {
+			fmt.Printf("Decryption failed on file metadata! The key is wrong or the metadata was tampered with: %v\n", err)
+			os.Exit(1)
+		}
+		fileName := safeFileName(fileMeta.Name, relayID)
+		fmt.Printf("Receiving file: %s (Size: %d bytes)\n", fileName, fileMeta.Size)
+
+		// 3. Create Output File
+		outputFileName := "downloaded_" + fileName
+		outFile, err := os.Create(outputFileName)
+		if err != nil {
+			fmt.Printf("Failed to create output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer outFile.Close()
+
+		// 4. Decrypt frames in order, acknowledging each so the sender can continue
+		fileHasher := sha256.New()
+		var written int64
+		for index := uint64(0); ; index++ {
+			kind, frame, err := conn.ReadMessage()
+			if err != nil || kind != websocket.BinaryMessage {
+				fmt.Printf("\nTransfer interrupted: %v\n", relayCloseReason(err))
+				os.Remove(outputFileName)
+				os.Exit(1)
+			}
+
+			plaintext, final, err := crypto.OpenRelayChunk(suite, key, index, frame)
+			if err != nil {
+				fmt.Printf("\nDecryption failed on chunk %d! The data may be corrupted or the key is wrong: %v\n", index, err)
+				os.Remove(outputFileName)
+				os.Exit(1)
+			}
+			if _, err := outFile.Write(plaintext); err != nil {
+				fmt.Printf("\nFailed to write to file: %v\n", err)
+				os.Remove(outputFileName)
+				os.Exit(1)
+			}
+			fileHasher.Write(plaintext)
+			written += int64(len(plaintext))
+			fmt.Printf("\r   -> Received %s of %s", formatBytes(written), formatBytes(fileMeta.Size))
+
+			if err := conn.WriteJSON(client.RelayMessage{Type: "ack", Index: index}); err != nil {
+				fmt.Printf("\nTransfer interrupted: %v\n", err)
+				os.Remove(outputFileName)
+				os.Exit(1)
+			}
+			if final {
+				break
+			}
+		}
+
+		// 5. The final frame is authenticated, so a short file here means the sender lied about the size
+		if written != fileMeta.Size {
+			fmt.Printf("\nIntegrity check failed: received %d bytes, expected %d.\n", written, fileMeta.Size)
+			os.Remove(outputFileName)
+			os.Exit(1)
+		}
+		conn.WriteJSON(client.RelayMessage{Type: "done"})
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+
+		fmt.Println("\n\nTransfer Complete!")
+		fmt.Printf("Saved as: %s\n", outputFileName)
+		fmt.Printf("SHA-256 : %s\n", hex.EncodeToString(fileHasher.Sum(nil)))
+	},
+}
+
+// parseRelayURL splits a relay link into the server, the relay ID and the key.
+func parseRelayURL(inputURL string) (baseURL, relayID string, key []byte, err error) {
+	parsedURL, err := url.Parse(inputURL)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("invalid URL format: %w", err)
+	}
+
+	pathParts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if len(pathParts) != 2 || pathParts[0] != "relay" || pathParts[1] == "" {
+		return "", "", nil, fmt.Errorf("invalid URL path. Expected format: http://host/relay/<id>#k=<key>")
+	}
+	if !strings.HasPrefix(parsedURL.Fragment, "k=") {
+		return "", "", nil, fmt.Errorf("missing decryption key in URL fragment (#k=...)")
+	}
+
+	key, err = crypto.DecodeKey(strings.TrimPrefix(parsedURL.Fragment, "k="))
+	if err != nil {
+		return "", "", nil, err
+	}
+	return fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host), pathParts[1], key, nil
+}
+
+func init() {
+	rootCmd.AddCommand(receiveCmd)
+}
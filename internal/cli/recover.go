@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/sumanthd032/codedrop/internal/client"
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+var recoverCmd = &cobra.Command{
+	Use:   "recover [drop-id]",
+	Short: "Recover a lost drop URL with the organization escrow key",
+	Long: `When escrow_recipient is set in the config, push wraps every drop key to it.
+An admin holding the matching private key can rebuild the drop URL from the
+drop ID alone. Pass the escrow identity with --identity. This does not consume
+a download.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		serverURL, _ := cmd.Flags().GetString("server")
+		link := &dropURL{BaseURL: serverURL, DropID: args[0]}
+		api := client.NewAPIClient(serverURL)
+
+		// 1. Unwrap the escrowed key from the stanzas stored with the drop
+		key, err := resolveRecipientKey(link, api)
+		if err != nil {
+			fmt.Printf("Recovery failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 2. Check the key against the drop's metadata before handing out a URL
+		info, err := api.GetDropInfo(link.DropID)
+		if err != nil {
+			fmt.Printf("Failed to fetch drop info: %v\n", err)
+			os.Exit(1)
+		}
+		suite, err := crypto.LookupSuite(info.Algorithm)
+		if err != nil {
+			fmt.Printf("%v. Try upgrading codedrop.\n", err)
+			os.Exit(1)
+		}
+		fileMeta, err := crypto.OpenMetadata(suite, key, info.Metadata)
+		if err != nil {
+			fmt.Printf("Decryption failed on file metadata! The escrowed key is wrong or was tampered with: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 3. Print a plain key URL; it works whatever mode the drop was pushed with
+		recovered := fmt.Sprintf("%s/drop/%s#k=%s", serverURL, link.DropID, base64.URLEncoding.EncodeToString(key))
+		fmt.Printf("Recovered %s\n", safeFileName(fileMeta.Name, link.DropID))
+		fmt.Printf("Secure URL : %s\n", recovered)
+		fmt.Println("WARNING: Anyone with this URL can decrypt the file.")
+	},
+}
+
+// wrapStanzas wraps a drop key to each recipient and, when escrowRecipient is
+// set, to the organization escrow key as well.
+func wrapStanzas(key []byte, recipients []crypto.Recipient, escrowRecipient string) ([]*crypto.Stanza, error) {
+	if escrowRecipient != "" {
+		escrow, err := crypto.ParseRecipient(escrowRecipient)
+		if err != nil {
+			return nil, fmt.Errorf("escrow_recipient in config: %w", err)
+		}
+		recipients = append(recipients[:len(recipients):len(recipients)], escrow)
+	}
+
+	var wrapped []*crypto.Stanza
+	for _, r := range recipients {
+		stanza, err := r.Wrap(key)
+		if err != nil {
+			return nil, fmt.Errorf("wrapping key to %s: %w", r, err)
+		}
+		wrapped = append(wrapped, stanza)
+	}
+	return wrapped, nil
+}
+
+func init() {
+	rootCmd.AddCommand(recoverCmd)
+	recoverCmd.Flags().StringArrayVarP(&identityFiles, "identity", "i", nil, "Escrow identity file (repeatable)")
+}
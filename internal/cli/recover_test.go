@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sumanthd032/codedrop/internal/client"
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+func TestEscrowRecoversKey(t *testing.T) {
+	recipient, _ := crypto.GenerateX25519Identity()
+	escrow, _ := crypto.GenerateX25519Identity()
+	key, _, _ := crypto.GenerateKey()
+
+	// push --to with escrow_recipient set: one stanza per recipient, plus the escrow's
+	wrapped, err := wrapStanzas(key, []crypto.Recipient{recipient.Recipient()}, escrow.Recipient().String())
+	if err != nil {
+		t.Fatalf("Wrapping failed: %v", err)
+	}
+	if len(wrapped) != 2 {
+		t.Fatalf("Expected 2 stanzas (recipient and escrow), got %d", len(wrapped))
+	}
+	stanzas, err := crypto.MarshalStanzas(wrapped)
+	if err != nil {
+		t.Fatalf("Encoding stanzas failed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/drop/abc/info" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(client.DropInfoResponse{WrappedKeys: stanzas, MaxDownloads: 1})
+	}))
+	defer server.Close()
+
+	// recover only has the escrow identity
+	identityFile := filepath.Join(t.TempDir(), "escrow")
+	if err := os.WriteFile(identityFile, []byte(escrow.String()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	identityFiles = []string{identityFile}
+	defer func() { identityFiles = nil }()
+
+	link := &dropURL{BaseURL: server.URL, DropID: "abc"}
+	recovered, err := resolveRecipientKey(link, client.NewAPIClient(server.URL))
+	if err != nil {
+		t.Fatalf("Escrow identity failed to recover the key: %v", err)
+	}
+	if !bytes.Equal(recovered, key) {
+		t.Errorf("Recovered key does not match the drop key")
+	}
+}
+
+func TestWrapStanzasWithoutEscrow(t *testing.T) {
+	recipient, _ := crypto.GenerateX25519Identity()
+	key, _, _ := crypto.GenerateKey()
+
+	wrapped, err := wrapStanzas(key, []crypto.Recipient{recipient.Recipient()}, "")
+	if err != nil {
+		t.Fatalf("Wrapping failed: %v", err)
+	}
+	if len(wrapped) != 1 {
+		t.Errorf("Expected only the recipient's stanza, got %d", len(wrapped))
+	}
+
+	if _, err := wrapStanzas(key, nil, "not-a-recipient"); err == nil {
+		t.Errorf("Expected an invalid escrow_recipient to be rejected")
+	}
+}
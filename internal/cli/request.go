@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sumanthd032/codedrop/internal/client"
+)
+
+// requestPollInterval is how often 'codedrop request' checks whether the slot was filled
+const requestPollInterval = 5 * time.Second
+
+var (
+	requestRecipient string
+	requestNoWait    bool
+)
+
+var requestCmd = &cobra.Command{
+	Use:   "request",
+	Short: "Ask someone to send you a file, encrypted to your key",
+	Long: `Creates an empty drop on the server holding only your public key, and
+prints an upload URL. The other party runs 'codedrop push --into <url> <file>';
+the file is encrypted to you, and only your identity can pull it.
+
+The command waits and tells you when the upload is complete. With --no-wait it
+exits right away; pull the drop URL later with your identity.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		serverURL, _ := cmd.Flags().GetString("server")
+
+		// 1. Work out which public key the sender should encrypt to
+		recipient := requestRecipient
+		if recipient == "" {
+			identities, err := loadIdentities()
+			if err != nil {
+				fmt.Println("Error: no identity found. Run 'codedrop keygen' first or pass --recipient.")
+				os.Exit(1)
+			}
+			recipient = identities[0].Recipient().String()
+		} else {
+			recipients, err := parseRecipients([]string{recipient})
+			if err != nil || len(recipients) != 1 {
+				fmt.Printf("Error: --recipient must name exactly one public key: %v\n", err)
+				os.Exit(1)
+			}
+			recipient = recipients[0].String()
+		}
+
+		// 2. Create the empty slot
+		api := client.NewAPIClient(serverURL)
+		dropResp, err := api.CreateDrop(client.CreateDropRequest{
+			Recipient:    recipient,
+			ExpiresIn:    expire,
+			MaxDownloads: maxViews,
+		})
+		if err != nil {
+			fmt.Printf("Error creating request: %v\n", err)
+			os.Exit(1)
+		}
+		if err := saveOwnerToken(serverURL, dropResp); err != nil {
+			fmt.Printf("Warning: could not save the owner token: %v\n", err)
+		}
+
+		// The pin lets push --into notice if the server swaps in a different key
+		uploadURL := fmt.Sprintf("%s/request/%s#p=%s", serverURL, dropResp.DropID, requestPin(recipient))
+		dropLink := fmt.Sprintf("%s/drop/%s", serverURL, dropResp.DropID)
+
+		fmt.Println("--------------------------------------------------")
+		fmt.Printf("Upload URL : %s\n", uploadURL)
+		fmt.Printf("Recipient  : %s\n", recipient)
+		fmt.Printf("Expires At : %s\n", dropResp.ExpiresAt.Local().Format("Jan 02, 2006 15:04:05 MST"))
+		fmt.Println("--------------------------------------------------")
+		fmt.Printf("Send with: codedrop push --into \"%s\" <file>\n", uploadURL)
+
+		if requestNoWait {
+			fmt.Printf("Pull with: codedrop pull %s\n", dropLink)
+			return
+		}
+
+		// 3. Wait for the sender to fill the slot and seal it with a manifest
+		fmt.Println("Waiting for the upload... (Ctrl+C to stop waiting; the request stays open)")
+		if err := waitForUpload(api, dropResp.DropID, dropResp.ExpiresAt); err != nil {
+			fmt.Printf("Request closed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("\aUpload received!")
+		fmt.Printf("Pull with: codedrop pull %s\n", dropLink)
+	},
+}
+
+// waitForUpload polls the read-only info endpoint until the drop has a
+// manifest, so waiting never consumes a view.
+func waitForUpload(api *client.APIClient, dropID string, expiresAt time.Time) error {
+	uploading := false
+	for {
+		info, err := api.GetDropInfo(dropID)
+		switch {
+		case errors.Is(err, client.ErrAwaitingUpload):
+		case err != nil:
+			return err
+		case len(info.Manifest) > 0:
+			return nil
+		case !uploading:
+			uploading = true
+			fmt.Println("Sender connected. Upload in progress...")
+		}
+
+		if time.Now().After(expiresAt) {
+			return fmt.Errorf("it expired before the upload finished")
+		}
+		time.Sleep(requestPollInterval)
+	}
+}
+
+// requestPin is a short fingerprint of the requester's public key, carried in
+// the upload URL fragment so the server never sees it.
+func requestPin(recipient string) string {
+	sum := sha256.Sum256([]byte(recipient))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+// parseRequestURL splits an upload link, http://host/request/<id>#p=<pin>,
+// into the server, the drop ID and the pin.
+func parseRequestURL(inputURL string) (baseURL, dropID, pin string, err error) {
+	parsedURL, err := url.Parse(inputURL)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid URL format: %w", err)
+	}
+
+	pathParts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if len(pathParts) != 2 || pathParts[0] != "request" || pathParts[1] == "" {
+		return "", "", "", fmt.Errorf("invalid URL path. Expected format: http://host/request/<id>#p=<pin>")
+	}
+	if !strings.HasPrefix(parsedURL.Fragment, "p=") {
+		return "", "", "", fmt.Errorf("missing key pin in URL fragment (#p=...)")
+	}
+	return fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host), pathParts[1], strings.TrimPrefix(parsedURL.Fragment, "p="), nil
+}
+
+func init() {
+	rootCmd.AddCommand(requestCmd)
+	requestCmd.Flags().StringVarP(&expire, "expire", "e", "24h", "Time until the request and anything uploaded to it are deleted (e.g., 2h)")
+	requestCmd.Flags().IntVarP(&maxViews, "max-views", "m", 1, "Maximum number of times the received drop can be downloaded")
+	requestCmd.Flags().StringVarP(&requestRecipient, "recipient", "r", "", "Public key to receive with (default: your keygen identity)")
+	requestCmd.Flags().StringArrayVarP(&identityFiles, "identity", "i", nil, "Identity file whose public key to receive with")
+	requestCmd.Flags().BoolVar(&requestNoWait, "no-wait", false, "Print the upload URL and exit instead of waiting for the upload")
+}
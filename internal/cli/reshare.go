@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/sumanthd032/codedrop/internal/client"
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+var reshareOwnerToken string
+
+var reshareCmd = &cobra.Command{
+	Use:   "reshare [url] [share urls...]",
+	Short: "Issue a new link to an uploaded drop without uploading it again",
+	Long: `Creates a new drop that points at the same encrypted chunks as an existing
+one, with its own expiry and download limit. Nothing is uploaded again and no
+download of the original is consumed. The original keeps its own link, expiry
+and limit.
+
+Only the drop's owner can reshare it: push saves the owner token on this
+machine, or pass it with --owner-token. With --to, the key is wrapped to new
+recipients instead of reusing the original's key material; otherwise the new
+link decrypts exactly like the old one (same key, passphrase or key shares).
+Resharing a --recipients link gives a plain link carrying the key; the new drop
+has no grants of its own.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		link, err := parseDropURLs(args)
+		if err != nil {
+			fmt.Printf("Invalid URL: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 1. Find the owner token
+		ownerToken := reshareOwnerToken
+		if ownerToken == "" {
+			ownerToken, err = lookupOwnerToken(link.DropID)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// 2. Work out the key material for the new link. A plain key or key
+		// shares carry over as they are; a passphrase-wrapped key is bound to
+		// the drop ID and must be wrapped again. A grant link's key is fetched
+		// with the owner token, which doesn't spend one of its downloads.
+		api := client.NewAPIClient(link.BaseURL)
+		req := client.ReshareDropRequest{ExpiresIn: expire, MaxDownloads: maxViews}
+		toRecipients := len(recipientArgs) > 0
+		var key, wrappingKey []byte
+		switch {
+		case toRecipients:
+			if link.GrantKey != nil {
+				key, err = resolveOwnerGrantKey(link, api, ownerToken)
+			} else {
+				key, err = resolveDropKey(link, api)
+			}
+			if err == nil {
+				err = checkDropKey(api, link.DropID, key)
+			}
+			if err != nil {
+				fmt.Printf("Invalid key: %v\n", err)
+				os.Exit(1)
+			}
+			req.WrappedKeys, err = wrapToRecipients(key, recipientArgs)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Wrapping key to %d recipient(s)...\n", len(recipientArgs))
+		case link.WrappedKey != "":
+			key, wrappingKey, err = resolvePassphraseKey(link, api)
+			if err != nil {
+				fmt.Printf("Invalid key: %v\n", err)
+				os.Exit(1)
+			}
+		case link.GrantKey != nil:
+			// Grants belong to the original drop; the new link carries the key itself
+			key, err = resolveOwnerGrantKey(link, api, ownerToken)
+			if err != nil {
+				fmt.Printf("Invalid key: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// 3. Create the new drop on the server
+		fmt.Println("Contacting CodeDrop Server...")
+		dropResp, err := api.ReshareDrop(link.DropID, ownerToken, req)
+		if err != nil {
+			fmt.Printf("Error resharing drop: %v\n", err)
+			os.Exit(1)
+		}
+		if err := saveOwnerToken(link.BaseURL, dropResp); err != nil {
+			fmt.Printf("Warning: could not save the owner token (%v). To reshare later, pass --owner-token %s\n", err, dropResp.OwnerToken)
+		}
+
+		// 4. Build the new link(s)
+		dropLink := fmt.Sprintf("%s/drop/%s", link.BaseURL, dropResp.DropID)
+		var urls []string
+		switch {
+		case toRecipients:
+			urls = []string{dropLink}
+		case wrappingKey != nil:
+			wrapped, err := crypto.WrapKey(wrappingKey, key, dropResp.DropID)
+			if err != nil {
+				fmt.Printf("Error wrapping key: %v\n", err)
+				os.Exit(1)
+			}
+			urls = []string{dropLink + "#w=" + base64.URLEncoding.EncodeToString(wrapped)}
+		case link.GrantKey != nil:
+			urls = []string{dropLink + "#k=" + base64.URLEncoding.EncodeToString(key)}
+		case link.EncodedKey != "":
+			urls = []string{dropLink + "#k=" + link.EncodedKey}
+		case len(link.Shares) > 0:
+			for _, share := range link.Shares {
+				urls = append(urls, dropLink+"#s="+share)
+			}
+		default:
+			urls = []string{dropLink} // Recipient drop; the same recipients can pull it
+		}
+
+		fmt.Println("\nReshare Complete!")
+		fmt.Println("--------------------------------------------------")
+		if len(link.Shares) > 0 && !toRecipients {
+			for i, shareURL := range urls {
+				fmt.Printf("Share %d    : %s\n", i+1, shareURL)
+			}
+		} else {
+			fmt.Printf("Secure URL : %s\n", urls[0])
+		}
+		fmt.Printf("Expires At : %s\n", dropResp.ExpiresAt.Local().Format("Jan 02, 2006 15:04:05 MST"))
+		fmt.Printf("Max Views  : %d\n", maxViews)
+		fmt.Println("--------------------------------------------------")
+		if len(link.Shares) > 0 && !toRecipients {
+			fmt.Println("Key shares are unchanged; any other share works with the new drop ID in its URL.")
+		}
+		fmt.Println("The original link is unaffected and still expires on its own schedule.")
+	},
+}
+
+// checkDropKey makes sure a key opens the drop's metadata before it is wrapped
+// to anyone. It reads the info endpoint, so no download is consumed.
+func checkDropKey(api *client.APIClient, dropID string, key []byte) error {
+	info, err := api.GetDropInfo(dropID)
+	if err != nil {
+		return err
+	}
+	suite, err := crypto.LookupSuite(info.Algorithm)
+	if err != nil {
+		return err
+	}
+	if _, err := crypto.OpenMetadata(suite, key, info.Metadata); err != nil {
+		return fmt.Errorf("the key does not open this drop: %w", err)
+	}
+	return nil
+}
+
+// wrapToRecipients wraps a drop key to each recipient, and to the organization
+// escrow key when one is configured, as push --to does.
+func wrapToRecipients(key []byte, values []string) ([]byte, error) {
+	recipients, err := parseRecipients(values)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := wrapStanzas(key, recipients, cfg.EscrowRecipient)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.MarshalStanzas(wrapped)
+}
+
+func init() {
+	rootCmd.AddCommand(reshareCmd)
+	reshareCmd.Flags().StringVarP(&expire, "expire", "e", "24h", "Time until the new link is permanently deleted (e.g., 30m, 2h)")
+	reshareCmd.Flags().IntVarP(&maxViews, "max-views", "m", 1, "Maximum number of times the new link can be downloaded")
+	reshareCmd.Flags().StringArrayVarP(&recipientArgs, "to", "t", nil, "Wrap the key to a different recipient instead (repeatable)")
+	reshareCmd.Flags().StringVar(&reshareOwnerToken, "owner-token", "", "Owner token of the drop (default: the one saved by push)")
+	reshareCmd.Flags().StringArrayVarP(&identityFiles, "identity", "i", nil, "Identity file, when the original drop was pushed with --to")
+	reshareCmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "Read the passphrase for a protected drop from this file")
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/sumanthd032/codedrop/internal/client"
+)
+
+var revokeOwnerToken string
+
+var revokeCmd = &cobra.Command{
+	Use:   "revoke [url or drop id] [names...]",
+	Short: "Revoke one person's link to a drop pushed with --recipients",
+	Long: `Revokes the named people's links to a drop pushed with --recipients. Their
+wrapped key is deleted from the server, so a revoked link stops working even if
+it has downloads left. Everyone else's links are unaffected.
+
+Given one person's own link and no names, revokes that link.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		link, ownerToken, err := resolveOwnedDrop(args[0], revokeOwnerToken)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		api := client.NewAPIClient(link.BaseURL)
+
+		// 1. Work out which grants to revoke
+		names := args[1:]
+		var grantIDs []string
+		switch {
+		case len(names) > 0:
+			status, err := api.GetDropStatus(link.DropID, ownerToken)
+			if err != nil {
+				fmt.Printf("Failed to fetch drop status: %v\n", err)
+				os.Exit(1)
+			}
+			byLabel := map[string]string{}
+			for _, g := range status.Grants {
+				byLabel[g.Label] = g.ID
+			}
+			for _, name := range names {
+				id, ok := byLabel[name]
+				if !ok {
+					fmt.Printf("Error: drop %s has no recipient named %q\n", link.DropID, name)
+					os.Exit(1)
+				}
+				grantIDs = append(grantIDs, id)
+			}
+		case link.GrantID != "":
+			names = []string{"this link"}
+			grantIDs = []string{link.GrantID}
+		default:
+			fmt.Println("Error: name the recipients to revoke, or pass their own link")
+			os.Exit(1)
+		}
+
+		// 2. Revoke them
+		failed := false
+		for i, id := range grantIDs {
+			if err := api.RevokeGrant(link.DropID, id, ownerToken); err != nil {
+				fmt.Printf("Failed to revoke %s: %v\n", names[i], err)
+				failed = true
+				continue
+			}
+			fmt.Printf("Revoked %s\n", names[i])
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(revokeCmd)
+	revokeCmd.Flags().StringVar(&revokeOwnerToken, "owner-token", "", "Owner token of the drop (default: the one saved by push)")
+}
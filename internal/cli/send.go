@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spf13/cobra"
+	"github.com/sumanthd032/codedrop/internal/client"
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+// Relay transfers use smaller chunks than push so backpressure is responsive,
+// and the sender keeps at most relayWindow chunks unacknowledged.
+const (
+	relayChunkSize = 1024 * 1024 // 1MB chunks
+	relayWindow    = 4
+)
+
+var relayWait string
+
+var sendCmd = &cobra.Command{
+	Use:   "send [file_path]",
+	Short: "Stream a file live to a receiver, without storing it on the server",
+	Long: `Opens a relay on the server and prints a URL. When the receiver runs
+'codedrop receive' with it, the encrypted file streams through the server
+directly; nothing is written to storage. The relay expires if nobody joins in time.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		filePath := args[0]
+		serverURL, _ := cmd.Flags().GetString("server")
+
+		// 1. Open the file
+		file, err := os.Open(filePath)
+		if err != nil {
+			fmt.Printf("Error opening file: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		fileInfo, err := file.Stat()
+		if err != nil {
+			fmt.Printf("Error reading file info: %v\n", err)
+			os.Exit(1)
+		}
+		if fileInfo.IsDir() {
+			fmt.Println("Error: CodeDrop currently only supports single files, not directories. Zip it first!")
+			os.Exit(1)
+		}
+
+		// 2. A fresh random key; with nothing stored there is nothing to deduplicate
+		key, encodedKey, err := crypto.GenerateKey()
+		if err != nil {
+			fmt.Printf("Error generating key: %v\n", err)
+			os.Exit(1)
+		}
+		suite, err := crypto.SelectSuite(cipherName, crypto.KeyPrivate)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		sealedMeta, err := crypto.SealMetadata(suite, key, &crypto.FileMetadata{
+			Name:     filepath.Base(fileInfo.Name()),
+			MimeType: detectMimeType(file),
+			Size:     fileInfo.Size(),
+			ModTime:  fileInfo.ModTime().UTC(),
+		})
+		if err != nil {
+			fmt.Printf("Error encrypting file metadata: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 3. Open the relay and wait for the receiver
+		fmt.Println("Opening relay...")
+		api := client.NewAPIClient(serverURL)
+		conn, err := api.DialRelaySend(relayWait)
+		if err != nil {
+			fmt.Printf("Error opening relay: %v\n", err)
+			os.Exit(1)
+		}
+		defer conn.Close()
+
+		var ready client.RelayControl
+		if err := conn.ReadJSON(&ready); err != nil || ready.Type != "ready" {
+			fmt.Printf("Error opening relay: unexpected response from server: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("--------------------------------------------------")
+		fmt.Printf("Relay URL  : %s/relay/%s#k=%s\n", serverURL, ready.RelayID, encodedKey)
+		fmt.Printf("Expires At : %s\n", ready.ExpiresAt.Local().Format("Jan 02, 2006 15:04:05 MST"))
+		fmt.Println("--------------------------------------------------")
+		fmt.Println("Run 'codedrop receive \"<url>\"' on the other machine. Waiting for the receiver...")
+
+		var connected client.RelayControl
+		if err := conn.ReadJSON(&connected); err != nil || connected.Type != "connected" {
+			fmt.Printf("\nRelay closed: %v\n", relayCloseReason(err))
+			os.Exit(1)
+		}
+		fmt.Println("Receiver connected. Streaming...")
+
+		// 4. Stream the file, never running more than relayWindow chunks ahead
+		if err := conn.WriteJSON(client.RelayMessage{Type: "header", Algorithm: suite.ID, Metadata: sealedMeta}); err != nil {
+			fmt.Printf("Error sending header: %v\n", err)
+			os.Exit(1)
+		}
+
+		acks := make(chan uint64, relayWindow)
+		done := make(chan error, 1)
+		go func() {
+			for {
+				var msg client.RelayMessage
+				if err := conn.ReadJSON(&msg); err != nil {
+					done <- fmt.Errorf("receiver disconnected: %v", relayCloseReason(err))
+					return
+				}
+				switch msg.Type {
+				case "ack":
+					acks <- msg.Index
+				case "done":
+					done <- nil
+					return
+				}
+			}
+		}()
+
+		buffer := make([]byte, relayChunkSize)
+		remaining := fileInfo.Size()
+		var index, acked uint64
+		for final := false; !final; index++ {
+			for index-acked >= relayWindow {
+				select {
+				case <-acks:
+					acked++
+				case err := <-done:
+					fmt.Printf("\nTransfer failed: %v\n", orEarlyDone(err))
+					os.Exit(1)
+				}
+			}
+
+			n, err := io.ReadFull(file, buffer[:min(int64(relayChunkSize), remaining)])
+			if err != nil && err != io.EOF {
+				fmt.Printf("\nError reading file: %v\n", err)
+				os.Exit(1)
+			}
+			remaining -= int64(n)
+			final = remaining == 0
+
+			frame, err := crypto.SealRelayChunk(suite, key, index, final, buffer[:n])
+			if err != nil {
+				fmt.Printf("\nError encrypting chunk: %v\n", err)
+				os.Exit(1)
+			}
+			if err := conn.WriteMessage(websocket.BinaryMessage, frame); err != nil {
+				fmt.Printf("\nTransfer failed: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\r   -> Sent %s of %s", formatBytes(fileInfo.Size()-remaining), formatBytes(fileInfo.Size()))
+		}
+
+		// 5. Wait for the receiver to confirm it has everything
+	wait:
+		for {
+			select {
+			case <-acks:
+			case err := <-done:
+				if err != nil {
+					fmt.Printf("\nTransfer failed: %v\n", err)
+					os.Exit(1)
+				}
+				break wait
+			}
+		}
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+
+		fmt.Println("\n\nTransfer Complete! The receiver has the file; nothing was stored on the server.")
+	},
+}
+
+// relayCloseReason turns a relay read error into something readable
+func relayCloseReason(err error) string {
+	if ce, ok := err.(*websocket.CloseError); ok && ce.Text != "" {
+		return ce.Text
+	}
+	if err == nil {
+		return "unexpected message"
+	}
+	return err.Error()
+}
+
+// orEarlyDone explains a "done" that arrives before the whole file was sent
+func orEarlyDone(err error) error {
+	if err == nil {
+		return fmt.Errorf("receiver stopped before the transfer finished")
+	}
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(sendCmd)
+	sendCmd.Flags().StringVarP(&relayWait, "expire", "e", "10m", "How long to wait for the receiver to connect (max 1h)")
+	sendCmd.Flags().StringVar(&cipherName, "cipher", crypto.DefaultCipher, "Cipher for the transfer (aes-256-gcm, xchacha20-poly1305)")
+}
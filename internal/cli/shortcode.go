@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sumanthd032/codedrop/internal/client"
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+// codeWordCount is how many secret words follow the nameplate. Online, the
+// server allows only a handful of guesses, but it also holds the verifier and
+// the sealed fragment, so a compromised server could guess offline. Five words
+// are 45 bits, each guess an Argon2id evaluation, which puts that out of reach.
+const codeWordCount = 5
+
+// shortCodePattern matches codes like 7-crossbow-lantern-amber-anvil-bread.
+// Any number of words is accepted, so codes from older versions still redeem.
+var shortCodePattern = regexp.MustCompile(`^([0-9]+)-([a-z]+(?:-[a-z]+)+)$`)
+
+// createShortCode registers a short code that stands in for the drop's URL,
+// with the drop's owner token. The fragment is sealed under a key derived from
+// the words, which never leave this machine.
+func createShortCode(api *client.APIClient, dropID, ownerToken, fragment string) (string, error) {
+	words := make([]string, codeWordCount)
+	for i := range words {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeWords))))
+		if err != nil {
+			return "", fmt.Errorf("failed to pick code words: %w", err)
+		}
+		words[i] = codeWords[n.Int64()]
+	}
+	secretWords := strings.Join(words, "-")
+
+	params, err := crypto.NewKDFParams()
+	if err != nil {
+		return "", err
+	}
+	secret, err := crypto.DeriveShortCodeSecret(secretWords, params)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := crypto.SealShortCodeFragment(secret.SealKey, fragment, dropID)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := api.CreateShortCode(client.CreateShortCodeRequest{
+		DropID:    dropID,
+		KDFParams: params.String(),
+		Verifier:  secret.Verifier,
+		Fragment:  sealed,
+	}, ownerToken)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%s", resp.Nameplate, secretWords), nil
+}
+
+// resolveShortCode redeems a short code for the drop URL it stands for. Each
+// call uses up one of the code's few attempts on the server.
+func resolveShortCode(serverURL, code string) (string, error) {
+	m := shortCodePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(code)))
+	if m == nil {
+		return "", fmt.Errorf("invalid short code %q", code)
+	}
+	nameplate, err := strconv.Atoi(m[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid short code %q", code)
+	}
+
+	// 1. Stretch the words with the parameters the sender chose
+	api := client.NewAPIClient(serverURL)
+	paramsResp, err := api.GetShortCode(nameplate)
+	if err != nil {
+		return "", err
+	}
+	params, err := crypto.ParseKDFParams(paramsResp.KDFParams)
+	if err != nil {
+		return "", err
+	}
+	secret, err := crypto.DeriveShortCodeSecret(m[2], params)
+	if err != nil {
+		return "", err
+	}
+
+	// 2. SPAKE2 with the server; only the right words open its answer
+	pake, err := crypto.NewPAKEClient(secret.Verifier, []byte(m[1]))
+	if err != nil {
+		return "", err
+	}
+	claimResp, err := api.ClaimShortCode(nameplate, client.ClaimShortCodeRequest{Message: pake.Message()})
+	if err != nil {
+		return "", err
+	}
+	sessionKey, err := pake.Finish(claimResp.Message)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := crypto.OpenShortCodeClaim(sessionKey, claimResp.Claim)
+	if err != nil {
+		return "", err
+	}
+	var claim client.ShortCodeClaim
+	if err := json.Unmarshal(plaintext, &claim); err != nil {
+		return "", fmt.Errorf("invalid short code claim: %w", err)
+	}
+
+	// 3. Open the fragment the sender sealed
+	fragment, err := crypto.OpenShortCodeFragment(secret.SealKey, claim.Fragment, claim.DropID)
+	if err != nil {
+		return "", err
+	}
+	dropURL := fmt.Sprintf("%s/drop/%s", strings.TrimRight(serverURL, "/"), claim.DropID)
+	if fragment != "" {
+		dropURL += "#" + fragment
+	}
+	return dropURL, nil
+}
+
+// expandShortCodes replaces any short codes in a command's arguments with
+// the URLs they stand for, using the --server flag.
+func expandShortCodes(cmd *cobra.Command, args []string) ([]string, error) {
+	serverURL, _ := cmd.Flags().GetString("server")
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		expanded[i] = arg
+		if shortCodePattern.MatchString(strings.ToLower(strings.TrimSpace(arg))) {
+			dropURL, err := resolveShortCode(serverURL, arg)
+			if err != nil {
+				return nil, err
+			}
+			expanded[i] = dropURL
+		}
+	}
+	return expanded, nil
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/term"
+)
+
+// loadSigner returns an Ed25519 signer for push --sign. A private key file is
+// used directly; a public key file (e.g. id_ed25519.pub) means the matching
+// private key lives in ssh-agent.
+func loadSigner(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	if pub, _, _, _, err := ssh.ParseAuthorizedKey(data); err == nil {
+		return agentSigner(pub)
+	}
+
+	signer, err := ssh.ParsePrivateKey(data)
+	if _, missing := err.(*ssh.PassphraseMissingError); missing {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return nil, fmt.Errorf("signing key %s is encrypted: load it into ssh-agent and pass the .pub file instead", path)
+		}
+		var pass []byte
+		if pass, err = promptSecret(fmt.Sprintf("Passphrase for %s: ", path)); err != nil {
+			return nil, err
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(data, pass)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+	return signer, nil
+}
+
+// agentKey is an ssh-agent key. It signs over the agent connection, which stays
+// open until closeSigner.
+type agentKey struct {
+	ssh.Signer
+	conn net.Conn
+}
+
+// agentSigner finds the ssh-agent key matching pub.
+func agentSigner(pub ssh.PublicKey) (ssh.Signer, error) {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, fmt.Errorf("signing with a public key needs ssh-agent, but SSH_AUTH_SOCK is not set")
+	}
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh-agent: %w", err)
+	}
+
+	signers, err := agent.NewClient(conn).Signers()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to list ssh-agent keys: %w", err)
+	}
+	for _, s := range signers {
+		if bytes.Equal(s.PublicKey().Marshal(), pub.Marshal()) {
+			return &agentKey{Signer: s, conn: conn}, nil
+		}
+	}
+	conn.Close()
+	return nil, fmt.Errorf("ssh-agent does not hold the key %s", ssh.FingerprintSHA256(pub))
+}
+
+// closeSigner releases the agent connection behind a signer from loadSigner.
+// Key files hold nothing open.
+func closeSigner(signer ssh.Signer) {
+	if key, ok := signer.(*agentKey); ok {
+		key.conn.Close()
+	}
+}
+
+// trustedSenders maps key fingerprints to the names given in the trusted
+// senders file, which uses authorized_keys format: "ssh-ed25519 AAAA... alice".
+type trustedSenders map[string]string
+
+// loadTrustedSenders reads the file from the config (default
+// ~/.config/codedrop/trusted_senders). A missing file means nobody is trusted.
+func loadTrustedSenders(cfg *Config) (trustedSenders, error) {
+	path := cfg.TrustedSenders
+	if path == "" {
+		dir, err := configDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "trusted_senders")
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return trustedSenders{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted senders: %w", err)
+	}
+
+	trusted := trustedSenders{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pub, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		if comment == "" {
+			comment = "(unnamed)"
+		}
+		trusted[ssh.FingerprintSHA256(pub)] = comment
+	}
+	return trusted, scanner.Err()
+}
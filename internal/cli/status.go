@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sumanthd032/codedrop/internal/client"
+)
+
+var statusOwnerToken string
+
+var statusCmd = &cobra.Command{
+	Use:   "status [url or drop id]",
+	Short: "Show who has downloaded a drop you pushed",
+	Long: `Shows a drop's downloads and expiry. For drops pushed with --recipients it
+shows each person's link separately: how many downloads they have used, when
+they last downloaded, and whether the link was revoked.
+
+Without an argument, shows every unexpired drop pushed from this machine.
+Only the drop's owner can see its status: push saves the owner token on this
+machine, or pass it with --owner-token.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// 1. A single drop
+		if len(args) == 1 {
+			link, ownerToken, err := resolveOwnedDrop(args[0], statusOwnerToken)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			status, err := client.NewAPIClient(link.BaseURL).GetDropStatus(link.DropID, ownerToken)
+			if err != nil {
+				fmt.Printf("Failed to fetch drop status: %v\n", err)
+				os.Exit(1)
+			}
+			printDropStatus(link.DropID, status)
+			return
+		}
+
+		// 2. Every drop pushed from this machine, soonest to expire first
+		drops, err := loadOwnedDrops()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		var ids []string
+		for id, d := range drops {
+			if time.Now().Before(d.ExpiresAt) {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			fmt.Println("No unexpired drops were pushed from this machine.")
+			return
+		}
+		sort.Slice(ids, func(i, j int) bool { return drops[ids[i]].ExpiresAt.Before(drops[ids[j]].ExpiresAt) })
+
+		for _, id := range ids {
+			d := drops[id]
+			status, err := client.NewAPIClient(d.Server).GetDropStatus(id, d.OwnerToken)
+			if err != nil {
+				fmt.Printf("\nDrop %s: %v\n", id, err)
+				continue
+			}
+			printDropStatus(id, status)
+		}
+	},
+}
+
+// printDropStatus prints one drop's status, with a line per access grant
+func printDropStatus(dropID string, status *client.DropStatusResponse) {
+	fmt.Printf("\n=== Drop %s ===\n", dropID)
+	if status.NotBefore != nil {
+		fmt.Printf("Opens At    : %s\n", status.NotBefore.Local().Format("Jan 02, 2006 15:04:05 MST"))
+	}
+	fmt.Printf("Expires At  : %s\n", status.ExpiresAt.Local().Format("Jan 02, 2006 15:04:05 MST"))
+	if len(status.Grants) == 0 {
+		fmt.Printf("Downloads   : %d of %d\n", status.Downloads, status.MaxDownloads)
+		return
+	}
+
+	fmt.Println("Recipients  :")
+	for _, g := range status.Grants {
+		state := "never downloaded"
+		if g.LastDownloadAt != nil {
+			state = "last downloaded " + g.LastDownloadAt.Local().Format("Jan 02, 2006 15:04:05 MST")
+		}
+		if g.RevokedAt != nil {
+			state += ", revoked " + g.RevokedAt.Local().Format("Jan 02, 2006 15:04:05 MST")
+		}
+		fmt.Printf("  %-12s %d of %d  (%s)\n", g.Label, g.Downloads, g.MaxDownloads, state)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().StringVar(&statusOwnerToken, "owner-token", "", "Owner token of the drop (default: the one saved by push)")
+}
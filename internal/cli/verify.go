@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/sumanthd032/codedrop/internal/client"
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [url or short code] [share urls...] [file]",
+	Short: "Check a local file against a drop without downloading it",
+	Long: `Hashes the local file and compares it with the drop's key and encrypted
+manifest, then cross-checks size and chunk count. For a bundle, the file is
+compared with the entry of the same name. Only metadata is fetched, so no
+download is consumed.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		filePath := args[len(args)-1]
+		urls, err := expandShortCodes(cmd, args[:len(args)-1])
+		if err != nil {
+			fmt.Printf("Short code failed: %v\n", err)
+			os.Exit(1)
+		}
+		link, err := parseDropURLs(urls)
+		if err != nil {
+			fmt.Printf("Invalid URL: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 1. Hash the local file
+		file, err := os.Open(filePath)
+		if err != nil {
+			fmt.Printf("Error opening file: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		hasher := sha256.New()
+		size, err := io.Copy(hasher, file)
+		if err != nil {
+			fmt.Printf("Error hashing file: %v\n", err)
+			os.Exit(1)
+		}
+		fileHash := hasher.Sum(nil)
+
+		// 2. Fetch and decrypt the drop's metadata through the non-consuming info endpoint
+		api := client.NewAPIClient(link.BaseURL)
+		key, err := resolveDropKey(link, api)
+		if err != nil {
+			fmt.Printf("Invalid key: %v\n", err)
+			os.Exit(1)
+		}
+
+		info, err := api.GetDropInfo(link.DropID)
+		if err != nil {
+			fmt.Printf("Failed to fetch drop info: %v\n", err)
+			os.Exit(1)
+		}
+
+		suite, err := crypto.LookupSuite(info.Algorithm)
+		if err != nil {
+			fmt.Printf("%v. Try upgrading codedrop.\n", err)
+			os.Exit(1)
+		}
+
+		fileMeta, err := crypto.OpenMetadata(suite, key, info.Metadata)
+		if err != nil {
+			fmt.Printf("Decryption failed on file metadata! The key is wrong or the metadata was tampered with: %v\n", err)
+			os.Exit(1)
+		}
+
+		var manifest *crypto.Manifest
+		if len(info.Manifest) > 0 {
+			manifest, err = crypto.OpenManifest(suite, key, info.Manifest)
+			if err != nil {
+				fmt.Printf("Decryption failed on manifest! The key is wrong or the manifest was tampered with: %v\n", err)
+				os.Exit(1)
+			}
+		} else if fileMeta.MimeType == crypto.BundleMimeType {
+			fmt.Println("The drop is a bundle whose upload is incomplete (no manifest yet); there is nothing to compare with.")
+			os.Exit(1)
+		}
+
+		// 3. Compare. A bundle is checked against the entry with the same name as the file.
+		var mismatches []string
+		dropFile := fmt.Sprintf("%s (%s)", safeFileName(fileMeta.Name, link.DropID), formatBytes(fileMeta.Size))
+		if manifest != nil && len(manifest.Entries) > 0 {
+			name := filepath.Base(filePath)
+			entry := manifest.Entry(name)
+			if entry == nil {
+				fmt.Printf("The drop is a bundle of %d files and none is named %s.\n", len(manifest.Entries), name)
+				os.Exit(1)
+			}
+			dropFile = fmt.Sprintf("%s (%s), in a bundle of %d files", safeFileName(entry.Name, link.DropID), formatBytes(entry.Size), len(manifest.Entries))
+			mismatches = compareBundleEntry(manifest, entry, info.ChunkCount, size, fileHash)
+		} else {
+			if manifest == nil {
+				fmt.Println("Note: upload incomplete (no manifest yet); chunk count not checked.")
+			}
+			mismatches = compareSingleFile(suite, key, manifest, info.KeyID, info.ChunkCount, fileMeta, size, fileHash)
+		}
+
+		fmt.Println("\n=== CodeDrop Verify ===")
+		fmt.Printf("Local File  : %s (%s)\n", filePath, formatBytes(size))
+		fmt.Printf("Drop File   : %s\n", dropFile)
+		fmt.Printf("SHA-256     : %s\n", hex.EncodeToString(fileHash))
+		if len(mismatches) > 0 {
+			fmt.Println("Result      : MISMATCH")
+			for _, m := range mismatches {
+				fmt.Printf("  - %s\n", m)
+			}
+			fmt.Println("=======================")
+			fmt.Println("No download was consumed.")
+			os.Exit(1)
+		}
+		fmt.Println("Result      : MATCH")
+		fmt.Println("=======================")
+		fmt.Println("No download was consumed.")
+	},
+}
+
+// compareSingleFile checks a local file against a single-file drop. A convergent
+// key is the file hash itself; other modes rely on the manifest, which is nil
+// while the upload is incomplete.
+func compareSingleFile(suite *crypto.Suite, key []byte, manifest *crypto.Manifest, keyID string, serverChunks int, fileMeta *crypto.FileMetadata, size int64, fileHash []byte) []string {
+	var mismatches []string
+	if err := verifyConvergentKey(suite, &crypto.Manifest{KeyID: keyID}, key, fileHash); err != nil {
+		mismatches = append(mismatches, err.Error())
+	}
+	if fileMeta.Size != size {
+		mismatches = append(mismatches, fmt.Sprintf("size is %d bytes, drop has %d", size, fileMeta.Size))
+	}
+
+	if manifest == nil {
+		return mismatches
+	}
+	if manifest.FileHash != hex.EncodeToString(fileHash) {
+		mismatches = append(mismatches, "SHA-256 differs from the one recorded in the drop")
+	}
+	paddedSize, err := crypto.PaddedSize(manifest.Padding, size)
+	if err != nil {
+		return append(mismatches, err.Error())
+	}
+	return append(mismatches, compareChunkCount(manifest, serverChunks, manifest.ChunkCount, paddedSize)...)
+}
+
+// compareBundleEntry checks a local file against the bundle entry of the same
+// name. Bundle keys cover every entry, so only the manifest can be compared.
+func compareBundleEntry(manifest *crypto.Manifest, entry *crypto.BundleEntry, serverChunks int, size int64, fileHash []byte) []string {
+	var mismatches []string
+	if entry.Size != size {
+		mismatches = append(mismatches, fmt.Sprintf("size is %d bytes, bundle entry has %d", size, entry.Size))
+	}
+	if entry.FileHash != hex.EncodeToString(fileHash) {
+		mismatches = append(mismatches, "SHA-256 differs from the one recorded in the bundle")
+	}
+
+	// Padding for the whole bundle is appended to its last entry
+	streamSize := size
+	if entry == &manifest.Entries[len(manifest.Entries)-1] {
+		paddedSize, err := crypto.PaddedSize(manifest.Padding, manifest.Size)
+		if err != nil {
+			return append(mismatches, err.Error())
+		}
+		streamSize += paddedSize - manifest.Size
+	}
+	return append(mismatches, compareChunkCount(manifest, serverChunks, entry.ChunkCount, streamSize)...)
+}
+
+// compareChunkCount checks the server agrees with the manifest, and that
+// streamSize bytes would have uploaded as the chunks the drop has.
+func compareChunkCount(manifest *crypto.Manifest, serverChunks, chunks int, streamSize int64) []string {
+	if manifest.ChunkCount != serverChunks {
+		return []string{fmt.Sprintf("manifest lists %d chunks, server holds %d", manifest.ChunkCount, serverChunks)}
+	}
+	if expected := int((streamSize + chunkSize - 1) / chunkSize); chunks != expected {
+		return []string{fmt.Sprintf("file would upload as %d chunks, drop has %d", expected, chunks)}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().StringArrayVarP(&identityFiles, "identity", "i", nil, "Identity file for drops encrypted with push --to (repeatable)")
+	verifyCmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "Read the passphrase for a protected drop from this file")
+}
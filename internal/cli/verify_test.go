@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/sumanthd032/codedrop/internal/crypto"
+)
+
+func TestCompareSingleFile(t *testing.T) {
+	content := []byte("Verify Test")
+	hash := sha256.Sum256(content)
+	size := int64(len(content))
+	convergent, _ := crypto.SelectSuite(crypto.DefaultCipher, crypto.KeyConvergent)
+	private, _ := crypto.SelectSuite(crypto.DefaultCipher, crypto.KeyPrivate)
+	randomKey, _, _ := crypto.GenerateKey()
+
+	manifest := &crypto.Manifest{Size: size, FileHash: hex.EncodeToString(hash[:]), ChunkCount: 1}
+	fileMeta := &crypto.FileMetadata{Name: "a.txt", Size: size}
+	otherHash := sha256.Sum256([]byte("Something else"))
+
+	tests := []struct {
+		name         string
+		suite        *crypto.Suite
+		key          []byte
+		manifest     *crypto.Manifest
+		serverChunks int
+		size         int64
+		fileHash     []byte
+		want         []string // Substrings of the expected mismatches, in order
+	}{
+		{"convergent match", convergent, hash[:], manifest, 1, size, hash[:], nil},
+		{"private match", private, randomKey, manifest, 1, size, hash[:], nil},
+		{"convergent key differs", convergent, hash[:], manifest, 1, size, otherHash[:], []string{"convergent key", "SHA-256 differs"}},
+		{"private hash differs", private, randomKey, manifest, 1, size, otherHash[:], []string{"SHA-256 differs"}},
+		{"size differs", private, randomKey, manifest, 1, size + 1, hash[:], []string{"size is"}},
+		{"server chunk count differs", private, randomKey, manifest, 2, size, hash[:], []string{"server holds 2"}},
+		{"no manifest yet", convergent, hash[:], nil, 0, size, hash[:], nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareSingleFile(tt.suite, tt.key, tt.manifest, "", tt.serverChunks, fileMeta, tt.size, tt.fileHash)
+			checkMismatches(t, got, tt.want)
+		})
+	}
+}
+
+func TestCompareBundleEntry(t *testing.T) {
+	first := sha256.Sum256([]byte("first"))
+	second := sha256.Sum256([]byte("second"))
+	manifest := &crypto.Manifest{
+		Size:       10,
+		ChunkCount: 2,
+		Entries: []crypto.BundleEntry{
+			{Name: "a.txt", Size: 5, FileHash: hex.EncodeToString(first[:]), FirstChunk: 0, ChunkCount: 1},
+			{Name: "b.txt", Size: 5, FileHash: hex.EncodeToString(second[:]), FirstChunk: 1, ChunkCount: 1},
+		},
+	}
+	entry := manifest.Entry("b.txt")
+
+	tests := []struct {
+		name         string
+		serverChunks int
+		size         int64
+		fileHash     []byte
+		want         []string
+	}{
+		{"match", 2, 5, second[:], nil},
+		{"hash differs", 2, 5, first[:], []string{"SHA-256 differs"}},
+		{"size differs", 2, 6, second[:], []string{"size is 6 bytes"}},
+		{"server chunk count differs", 3, 5, second[:], []string{"server holds 3"}},
+		{"too large for its chunks", 2, chunkSize + 1, second[:], []string{"size is", "upload as 2 chunks"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareBundleEntry(manifest, entry, tt.serverChunks, tt.size, tt.fileHash)
+			checkMismatches(t, got, tt.want)
+		})
+	}
+}
+
+func checkMismatches(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d mismatches, got %q", len(want), got)
+	}
+	for i := range want {
+		if !strings.Contains(got[i], want[i]) {
+			t.Errorf("Mismatch %d is %q, expected it to mention %q", i, got[i], want[i])
+		}
+	}
+}
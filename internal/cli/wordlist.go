@@ -0,0 +1,72 @@
+package cli
+
+// codeWords is the short-code word list: 512 distinct, easy to spell words,
+// so each word carries 9 bits. Changing it breaks codes that are still live.
+var codeWords = [512]string{
+	"acorn", "adrift", "alpine", "amber", "anchor", "angle", "ankle", "anthem",
+	"antler", "anvil", "apple", "apron", "arcade", "arch", "arctic", "arena",
+	"armor", "arrow", "ash", "aspen", "atlas", "attic", "august", "aurora",
+	"autumn", "avenue", "axis", "badge", "badger", "bagel", "bakery", "balcony",
+	"ballad", "bamboo", "bandit", "banjo", "banner", "barley", "barn", "barrel",
+	"basil", "basin", "basket", "beacon", "beagle", "beam", "bean", "beaver",
+	"beetle", "bell", "bench", "berry", "birch", "biscuit", "bison", "blanket",
+	"blizzard", "blossom", "blue", "boat", "bobcat", "bonfire", "bongo", "book",
+	"boots", "border", "bottle", "boulder", "bounty", "bramble", "brass", "bread",
+	"breeze", "brick", "bridge", "brook", "broom", "bubble", "bucket", "buckle",
+	"buffalo", "bugle", "bundle", "burrow", "butter", "button", "cabbage",
+	"cabin", "cactus", "cadet", "camel", "camera", "canal", "canary", "candle",
+	"canoe", "canyon", "captain", "caramel", "cargo", "carpet", "carrot",
+	"cashew", "castle", "cedar", "cellar", "cello", "chalk", "channel", "chapel",
+	"cheetah", "cherry", "chess", "chestnut", "chimney", "chisel", "cider",
+	"cinder", "circle", "citrus", "clam", "clay", "cliff", "clock", "clover",
+	"cobalt", "cobra", "cocoa", "comet", "compass", "condor", "copper", "coral",
+	"corner", "cosmos", "cotton", "cougar", "cove", "coyote", "crab", "cradle",
+	"crane", "crater", "crayon", "creek", "cricket", "crimson", "crossbow",
+	"crown", "crystal", "cuckoo", "cupcake", "curtain", "cushion", "cypress",
+	"dagger", "dahlia", "daisy", "dancer", "dawn", "delta", "denim", "desert",
+	"dewdrop", "diamond", "dingo", "dipper", "dolphin", "domino", "donkey",
+	"dove", "dragon", "drizzle", "druid", "drum", "dune", "dynamo", "eagle",
+	"easel", "echo", "eclipse", "eel", "elbow", "elder", "elm", "ember",
+	"emerald", "emu", "engine", "fable", "falcon", "feather", "fern", "ferret",
+	"ferry", "fiddle", "field", "fiesta", "fig", "finch", "firefly", "fjord",
+	"flag", "flame", "flamingo", "fleece", "flint", "flora", "flute", "forest",
+	"forge", "fossil", "fountain", "fox", "frost", "gadget", "galaxy", "gale",
+	"gander", "garden", "garnet", "gazelle", "gecko", "geyser", "ginger",
+	"glacier", "glider", "globe", "goblet", "goose", "gopher", "gourd", "granite",
+	"grape", "gravel", "griffin", "grove", "guitar", "gull", "gust", "halo",
+	"hammer", "harbor", "harp", "harvest", "hawk", "hazel", "heather", "hedgehog",
+	"helmet", "hermit", "heron", "hickory", "hill", "hive", "honey", "hoop",
+	"horizon", "hornet", "husky", "hyena", "iceberg", "igloo", "indigo", "ink",
+	"iris", "island", "ivory", "jackal", "jacket", "jade", "jaguar", "jasmine",
+	"jelly", "jester", "jetty", "jigsaw", "jungle", "juniper", "kayak", "kelp",
+	"kernel", "kestrel", "kettle", "kite", "kiwi", "koala", "kudu", "ladder",
+	"lagoon", "lake", "lantern", "lapwing", "larch", "lark", "laurel", "lava",
+	"ledger", "lemon", "lentil", "lichen", "lilac", "lily", "lime", "limpet",
+	"linen", "lion", "lizard", "llama", "lobster", "locket", "loom", "lotus",
+	"lunar", "lupine", "lynx", "magnet", "magpie", "mallard", "manatee", "mango",
+	"mantis", "maple", "marble", "marlin", "marsh", "meadow", "melon", "merlin",
+	"mesa", "meteor", "mink", "minnow", "mint", "mirror", "mistral", "mitten",
+	"mole", "mongoose", "monsoon", "moose", "mosaic", "moss", "moth", "mountain",
+	"muffin", "mustang", "nebula", "nectar", "needle", "nest", "newt", "nickel",
+	"nimbus", "nomad", "nugget", "nutmeg", "oak", "oasis", "oatmeal", "ocean",
+	"ocelot", "octopus", "olive", "onyx", "opal", "orbit", "orca", "orchard",
+	"orchid", "oriole", "osprey", "otter", "owl", "oyster", "paddle", "pagoda",
+	"palm", "panda", "panther", "paper", "paprika", "parade", "parrot", "parsley",
+	"pasta", "peach", "pearl", "pebble", "pecan", "pelican", "penguin", "pepper",
+	"petal", "pheasant", "piano", "pigeon", "pillow", "pine", "pioneer", "pirate",
+	"planet", "plum", "pocket", "polar", "pond", "poplar", "poppy", "prairie",
+	"prism", "pudding", "puffin", "pumpkin", "quartz", "quill", "quiver",
+	"rabbit", "raccoon", "radar", "radish", "rainbow", "raven", "reef", "ribbon",
+	"ridge", "river", "robin", "rocket", "rose", "ruby", "saddle", "saffron",
+	"sage", "salmon", "sandal", "sapphire", "satin", "scarf", "schooner",
+	"scroll", "seal", "shadow", "shell", "sierra", "silver", "sketch", "sled",
+	"sloth", "snail", "sonnet", "sparrow", "spider", "spruce", "squid",
+	"squirrel", "stable", "starfish", "statue", "stone", "storm", "summit",
+	"sunset", "swan", "sycamore", "tablet", "tango", "teapot", "temple",
+	"thistle", "thunder", "tiger", "timber", "toast", "topaz", "tornado",
+	"tortoise", "tower", "trail", "trumpet", "tulip", "tundra", "turnip",
+	"turtle", "twig", "umbrella", "unicorn", "valley", "vanilla", "velvet",
+	"violet", "violin", "voyage", "waffle", "walnut", "walrus", "wand", "warbler",
+	"water", "whale", "wheat", "whistle", "willow", "window", "winter", "wizard",
+	"wombat", "yacht", "yak", "yarrow", "zebra", "zephyr", "zinnia",
+}
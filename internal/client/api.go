@@ -3,32 +3,173 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
 // We redefine the models here to keep the CLI decoupled from the Server package
 type CreateDropRequest struct {
-	FileName       string `json:"file_name"`
+	Metadata       []byte         `json:"metadata"`
+	FileSize       int64          `json:"file_size"`
+	EncryptionSalt string         `json:"encryption_salt"`
+	Algorithm      string         `json:"algorithm"`
+	KeyID          string         `json:"key_id,omitempty"`
+	WrappedKeys    []byte         `json:"wrapped_keys,omitempty"`
+	ExpiresIn      string         `json:"expires_in"`
+	MaxDownloads   int            `json:"max_downloads"`
+	Recipient      string         `json:"recipient,omitempty"`
+	Grants         []GrantRequest `json:"grants,omitempty"`
+	NotBefore      *time.Time     `json:"not_before,omitempty"`
+	IdleTTL        string         `json:"idle_ttl,omitempty"`
+	GracePeriod    string         `json:"grace_period,omitempty"`
+}
+
+type GrantRequest struct {
+	ID         string `json:"id"`
+	Label      string `json:"label"`
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+type CreateDropResponse struct {
+	DropID      string    `json:"drop_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	OwnerToken  string    `json:"owner_token,omitempty"`
+	UploadToken string    `json:"upload_token,omitempty"`
+}
+
+type ReshareDropRequest struct {
+	ExpiresIn    string `json:"expires_in"`
+	MaxDownloads int    `json:"max_downloads"`
+	WrappedKeys  []byte `json:"wrapped_keys,omitempty"`
+}
+
+type RequestSlotResponse struct {
+	Recipient    string    `json:"recipient"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	MaxDownloads int       `json:"max_downloads"`
+}
+
+type FillRequestRequest struct {
+	Metadata       []byte `json:"metadata"`
 	FileSize       int64  `json:"file_size"`
 	EncryptionSalt string `json:"encryption_salt"`
-	ExpiresIn      string `json:"expires_in"`
-	MaxDownloads   int    `json:"max_downloads"`
+	Algorithm      string `json:"algorithm"`
+	KeyID          string `json:"key_id,omitempty"`
+	WrappedKeys    []byte `json:"wrapped_keys,omitempty"`
 }
 
-type CreateDropResponse struct {
-	DropID    string    `json:"drop_id"`
-	ExpiresAt time.Time `json:"expires_at"`
+// ErrAwaitingUpload is returned for a request slot nobody has filled yet, or a
+// drop whose upload has not finished
+var ErrAwaitingUpload = errors.New("this drop is still waiting for its upload")
+
+// ErrDropNotFound is returned for a drop that never existed or was deleted:
+// expired, or burned after its last download.
+var ErrDropNotFound = errors.New("drop not found: it has expired or reached its download limit and was deleted")
+
+// ErrGrantRevoked is returned for a grant link (push --recipients) the owner revoked
+var ErrGrantRevoked = errors.New("this link has been revoked by the sender")
+
+// NotYetAvailableError is returned for an embargoed drop (push --not-before)
+// before its time. The chunk endpoints answer the same way.
+type NotYetAvailableError struct {
+	At time.Time
+}
+
+func (e *NotYetAvailableError) Error() string {
+	return "this drop is not available until " + e.At.Local().Format("Jan 02, 2006 15:04:05 MST")
+}
+
+// notYetAvailable reads the opening time from a 425 Too Early response
+func notYetAvailable(resp *http.Response) error {
+	at, err := time.Parse(time.RFC3339, resp.Header.Get("X-Not-Before"))
+	if err != nil {
+		return fmt.Errorf("this drop is not available yet")
+	}
+	return &NotYetAvailableError{At: at}
 }
 
 // Add this struct near the top with the other models
 type GetDropMetadataResponse struct {
-	FileName       string `json:"file_name"`
-	FileSize       int64  `json:"file_size"`
-	EncryptionSalt string `json:"encryption_salt"`
-	ChunkCount     int    `json:"chunk_count"`
+	Metadata        []byte `json:"metadata"`
+	FileSize        int64  `json:"file_size"`
+	EncryptionSalt  string `json:"encryption_salt"`
+	Algorithm       string `json:"algorithm"`
+	KeyID           string `json:"key_id,omitempty"`
+	WrappedKeys     []byte `json:"wrapped_keys,omitempty"`
+	ChunkCount      int    `json:"chunk_count"`
+	Manifest        []byte `json:"manifest"`
+	DownloadToken   string `json:"download_token"`
+	GrantWrappedKey []byte `json:"grant_wrapped_key,omitempty"`
+}
+
+type DropInfoResponse struct {
+	Metadata       []byte     `json:"metadata"`
+	EncryptionSalt string     `json:"encryption_salt"`
+	Algorithm      string     `json:"algorithm"`
+	KeyID          string     `json:"key_id,omitempty"`
+	WrappedKeys    []byte     `json:"wrapped_keys,omitempty"`
+	FileSize       int64      `json:"file_size"`
+	ChunkCount     int        `json:"chunk_count"`
+	Manifest       []byte     `json:"manifest"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	MaxDownloads   int        `json:"max_downloads"`
+	Downloads      int        `json:"downloads"`
+	NotBefore      *time.Time `json:"not_before,omitempty"`
+}
+
+type DropStatusResponse struct {
+	ExpiresAt    time.Time     `json:"expires_at"`
+	MaxDownloads int           `json:"max_downloads"`
+	Downloads    int           `json:"downloads"`
+	Grants       []GrantStatus `json:"grants,omitempty"`
+	NotBefore    *time.Time    `json:"not_before,omitempty"`
+}
+
+type GrantStatus struct {
+	ID             string     `json:"id"`
+	Label          string     `json:"label"`
+	MaxDownloads   int        `json:"max_downloads"`
+	Downloads      int        `json:"downloads"`
+	LastDownloadAt *time.Time `json:"last_download_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
+
+type GrantKeyResponse struct {
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+type CreateShortCodeRequest struct {
+	DropID    string `json:"drop_id"`
+	KDFParams string `json:"kdf_params"`
+	Verifier  []byte `json:"verifier"`
+	Fragment  []byte `json:"fragment"`
+}
+
+type CreateShortCodeResponse struct {
+	Nameplate int `json:"nameplate"`
+}
+
+type ShortCodeParamsResponse struct {
+	KDFParams string `json:"kdf_params"`
+}
+
+type ClaimShortCodeRequest struct {
+	Message []byte `json:"message"`
+}
+
+type ClaimShortCodeResponse struct {
+	Message []byte `json:"message"`
+	Claim   []byte `json:"claim"`
+}
+
+type ShortCodeClaim struct {
+	DropID   string `json:"drop_id"`
+	Fragment []byte `json:"fragment"`
 }
 
 type APIClient struct {
@@ -74,8 +215,9 @@ func (c *APIClient) CreateDrop(req CreateDropRequest) (*CreateDropResponse, erro
 	return &dropResp, nil
 }
 
-// UploadChunk sends a single encrypted binary chunk
-func (c *APIClient) UploadChunk(dropID string, chunkIndex int, data []byte) error {
+// UploadChunk sends a single encrypted binary chunk. The token is the drop's
+// owner token, or the upload token when filling a request.
+func (c *APIClient) UploadChunk(dropID, token string, chunkIndex int, data []byte) error {
 	url := fmt.Sprintf("%s/api/v1/drop/%s/chunk", c.BaseURL, dropID)
 	
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
@@ -85,6 +227,33 @@ func (c *APIClient) UploadChunk(dropID string, chunkIndex int, data []byte) erro
 	
 	// Set our custom header so the server knows which piece this is
 	req.Header.Set("X-Chunk-Index", fmt.Sprintf("%d", chunkIndex))
+	req.Header.Set("X-Owner-Token", token)
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error (%d): %s", resp.StatusCode, string(msg))
+	}
+
+	return nil
+}
+
+// UploadManifest sends the encrypted manifest that seals the drop, with the
+// same token as UploadChunk
+func (c *APIClient) UploadManifest(dropID, token string, data []byte) error {
+	url := fmt.Sprintf("%s/api/v1/drop/%s/manifest", c.BaseURL, dropID)
+
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Owner-Token", token)
 	req.Header.Set("Content-Type", "application/octet-stream")
 
 	resp, err := c.HTTPClient.Do(req)
@@ -103,9 +272,17 @@ func (c *APIClient) UploadChunk(dropID string, chunkIndex int, data []byte) erro
 
 // GetDropMetadata fetches the file details before downloading
 func (c *APIClient) GetDropMetadata(dropID string) (*GetDropMetadataResponse, error) {
-	url := fmt.Sprintf("%s/api/v1/drop/%s", c.BaseURL, dropID)
-	
-	resp, err := c.HTTPClient.Get(url)
+	return c.getDropMetadata(fmt.Sprintf("%s/api/v1/drop/%s", c.BaseURL, dropID))
+}
+
+// GetGrantMetadata is GetDropMetadata through an access grant; the download
+// counts against that grant only, and comes with the grant's wrapped drop key
+func (c *APIClient) GetGrantMetadata(dropID, grantID string) (*GetDropMetadataResponse, error) {
+	return c.getDropMetadata(fmt.Sprintf("%s/api/v1/drop/%s?grant=%s", c.BaseURL, dropID, url.QueryEscape(grantID)))
+}
+
+func (c *APIClient) getDropMetadata(endpoint string) (*GetDropMetadataResponse, error) {
+	resp, err := c.HTTPClient.Get(endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("network error: %w", err)
 	}
@@ -116,6 +293,18 @@ func (c *APIClient) GetDropMetadata(dropID string) (*GetDropMetadataResponse, er
 		if resp.StatusCode == http.StatusGone {
 			return nil, fmt.Errorf("this drop has expired or reached its download limit")
 		}
+		if resp.StatusCode == http.StatusConflict {
+			return nil, ErrAwaitingUpload
+		}
+		if resp.StatusCode == http.StatusForbidden {
+			return nil, forbiddenError(msg)
+		}
+		if resp.StatusCode == http.StatusTooEarly {
+			return nil, notYetAvailable(resp)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrDropNotFound
+		}
 		return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, string(msg))
 	}
 
@@ -127,16 +316,272 @@ func (c *APIClient) GetDropMetadata(dropID string) (*GetDropMetadataResponse, er
 	return &metaResp, nil
 }
 
-// DownloadChunk retrieves a single encrypted binary chunk
-func (c *APIClient) DownloadChunk(dropID string, chunkIndex int) ([]byte, error) {
+// GetDropInfo fetches the drop details without consuming a download
+func (c *APIClient) GetDropInfo(dropID string) (*DropInfoResponse, error) {
+	return c.getDropInfo(fmt.Sprintf("%s/api/v1/drop/%s/info", c.BaseURL, dropID))
+}
+
+// GetGrantInfo is GetDropInfo through an access grant, with the grant's own
+// download count. It refuses revoked and used-up grants.
+func (c *APIClient) GetGrantInfo(dropID, grantID string) (*DropInfoResponse, error) {
+	return c.getDropInfo(fmt.Sprintf("%s/api/v1/drop/%s/info?grant=%s", c.BaseURL, dropID, url.QueryEscape(grantID)))
+}
+
+func (c *APIClient) getDropInfo(endpoint string) (*DropInfoResponse, error) {
+	resp, err := c.HTTPClient.Get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusGone {
+			return nil, fmt.Errorf("this drop has expired or reached its download limit")
+		}
+		if resp.StatusCode == http.StatusConflict {
+			return nil, ErrAwaitingUpload
+		}
+		if resp.StatusCode == http.StatusForbidden {
+			return nil, forbiddenError(msg)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrDropNotFound
+		}
+		return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, string(msg))
+	}
+
+	var infoResp DropInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&infoResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &infoResp, nil
+}
+
+// forbiddenError explains a 403 from the download endpoints: a revoked grant,
+// or a grants-only drop opened without one.
+func forbiddenError(msg []byte) error {
+	if strings.Contains(string(msg), "revoked") {
+		return ErrGrantRevoked
+	}
+	return fmt.Errorf("access denied: %s", strings.TrimSpace(string(msg)))
+}
+
+// GetRequest fetches the public key an open request slot is waiting for
+func (c *APIClient) GetRequest(dropID string) (*RequestSlotResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/request/%s", c.BaseURL, dropID)
+
+	resp, err := c.HTTPClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, requestError(resp)
+	}
+
+	var slotResp RequestSlotResponse
+	if err := json.NewDecoder(resp.Body).Decode(&slotResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &slotResp, nil
+}
+
+// FillRequest claims a request slot; chunks and the manifest are uploaded as for any drop
+func (c *APIClient) FillRequest(dropID string, req FillRequestRequest) (*CreateDropResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/request/%s", c.BaseURL, dropID)
+	body, _ := json.Marshal(req)
+
+	httpReq, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, requestError(resp)
+	}
+
+	var dropResp CreateDropResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dropResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &dropResp, nil
+}
+
+func requestError(resp *http.Response) error {
+	msg, _ := io.ReadAll(resp.Body)
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("request not found")
+	case http.StatusGone:
+		return fmt.Errorf("this request has expired")
+	case http.StatusConflict:
+		return fmt.Errorf("this request has already been filled or has expired")
+	}
+	return fmt.Errorf("server error (%d): %s", resp.StatusCode, string(msg))
+}
+
+// ReshareDrop creates a new link to an uploaded drop without uploading its chunks again
+func (c *APIClient) ReshareDrop(dropID, ownerToken string, req ReshareDropRequest) (*CreateDropResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/drop/%s/reshare", c.BaseURL, dropID)
+	body, _ := json.Marshal(req)
+
+	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Owner-Token", ownerToken)
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return nil, fmt.Errorf("drop not found or expired")
+		case http.StatusForbidden:
+			return nil, fmt.Errorf("the owner token does not match this drop")
+		case http.StatusConflict:
+			return nil, fmt.Errorf("the drop's upload is not complete")
+		}
+		return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, string(msg))
+	}
+
+	var dropResp CreateDropResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dropResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &dropResp, nil
+}
+
+// GetDropStatus shows the owner a drop's downloads, per grant for push --recipients
+func (c *APIClient) GetDropStatus(dropID, ownerToken string) (*DropStatusResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/drop/%s/status", c.BaseURL, dropID)
+
+	httpReq, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("X-Owner-Token", ownerToken)
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ownerError(resp)
+	}
+
+	var statusResp DropStatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&statusResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &statusResp, nil
+}
+
+// RevokeGrant revokes one access grant; its link stops working immediately
+func (c *APIClient) RevokeGrant(dropID, grantID, ownerToken string) error {
+	url := fmt.Sprintf("%s/api/v1/drop/%s/grant/%s", c.BaseURL, dropID, grantID)
+
+	httpReq, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("X-Owner-Token", ownerToken)
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return ownerError(resp)
+	}
+	return nil
+}
+
+// GetGrantKey fetches an access grant's wrapped drop key with the owner token
+func (c *APIClient) GetGrantKey(dropID, grantID, ownerToken string) ([]byte, error) {
+	url := fmt.Sprintf("%s/api/v1/drop/%s/grant/%s", c.BaseURL, dropID, grantID)
+
+	httpReq, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("X-Owner-Token", ownerToken)
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ownerError(resp)
+	}
+
+	var keyResp GrantKeyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&keyResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return keyResp.WrappedKey, nil
+}
+
+func ownerError(resp *http.Response) error {
+	msg, _ := io.ReadAll(resp.Body)
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("not found: %s", strings.TrimSpace(string(msg)))
+	case http.StatusForbidden:
+		if strings.Contains(string(msg), "revoked") {
+			return ErrGrantRevoked
+		}
+		return fmt.Errorf("the owner token does not match this drop")
+	}
+	return fmt.Errorf("server error (%d): %s", resp.StatusCode, string(msg))
+}
+
+// DownloadChunk retrieves a single encrypted binary chunk, with the download
+// token from GetDropMetadata
+func (c *APIClient) DownloadChunk(dropID, token string, chunkIndex int) ([]byte, error) {
 	url := fmt.Sprintf("%s/api/v1/drop/%s/chunk/%d", c.BaseURL, dropID, chunkIndex)
 	
-	resp, err := c.HTTPClient.Get(url)
+	httpReq, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("X-Download-Token", token)
+
+	resp, err := c.HTTPClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("network error: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooEarly {
+		return nil, notYetAvailable(resp)
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, forbiddenError(msg)
+	}
 	if resp.StatusCode != http.StatusOK {
 		msg, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, string(msg))
@@ -167,4 +612,89 @@ func (c *APIClient) GetStats() (*StatsResponse, error) {
 	}
 
 	return &statsResp, nil
-}
\ No newline at end of file
+}
+
+// CreateShortCode registers a short code for a drop with its owner token and
+// returns the code's nameplate
+func (c *APIClient) CreateShortCode(req CreateShortCodeRequest, ownerToken string) (*CreateShortCodeResponse, error) {
+	body, _ := json.Marshal(req)
+	httpReq, err := http.NewRequest(http.MethodPost, c.BaseURL+"/api/v1/code", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Owner-Token", ownerToken)
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, shortCodeError(resp)
+	}
+
+	var codeResp CreateShortCodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&codeResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &codeResp, nil
+}
+
+// GetShortCode fetches the KDF parameters for a short code's nameplate
+func (c *APIClient) GetShortCode(nameplate int) (*ShortCodeParamsResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/code/%d", c.BaseURL, nameplate)
+
+	resp, err := c.HTTPClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, shortCodeError(resp)
+	}
+
+	var paramsResp ShortCodeParamsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&paramsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &paramsResp, nil
+}
+
+// ClaimShortCode sends the client's SPAKE2 message. Each call uses up one of
+// the code's attempts, whether or not the code is right.
+func (c *APIClient) ClaimShortCode(nameplate int, req ClaimShortCodeRequest) (*ClaimShortCodeResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/code/%d/claim", c.BaseURL, nameplate)
+	body, _ := json.Marshal(req)
+
+	resp, err := c.HTTPClient.Post(url, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, shortCodeError(resp)
+	}
+
+	var claimResp ClaimShortCodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&claimResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &claimResp, nil
+}
+
+func shortCodeError(resp *http.Response) error {
+	msg, _ := io.ReadAll(resp.Body)
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("short code not found; it may have expired or been used up")
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("too many short code requests; wait a minute and try again")
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("only the drop's owner can create a short code for it")
+	}
+	return fmt.Errorf("server error (%d): %s", resp.StatusCode, string(msg))
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// RelayControl mirrors the server's control messages for a relay sender
+type RelayControl struct {
+	Type      string    `json:"type"`
+	RelayID   string    `json:"relay_id,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+// RelayMessage is the end-to-end protocol between send and receive. The
+// sender opens with a "header"; the receiver answers frames with "ack" and
+// finishes with "done". Encrypted chunks travel as binary messages.
+type RelayMessage struct {
+	Type      string `json:"type"`
+	Algorithm string `json:"algorithm,omitempty"` // header: cipher suite
+	Metadata  []byte `json:"metadata,omitempty"`  // header: sealed file metadata
+	Index     uint64 `json:"index,omitempty"`     // ack: frame index
+}
+
+// DialRelaySend opens a new rendezvous. The first message on the connection
+// is a "ready" RelayControl carrying its ID.
+func (c *APIClient) DialRelaySend(expiresIn string) (*websocket.Conn, error) {
+	endpoint := c.relayURL("/api/v1/relay")
+	if expiresIn != "" {
+		endpoint += "?expires_in=" + url.QueryEscape(expiresIn)
+	}
+	return dialRelay(endpoint)
+}
+
+// DialRelayReceive joins the rendezvous a sender is waiting on
+func (c *APIClient) DialRelayReceive(relayID string) (*websocket.Conn, error) {
+	return dialRelay(c.relayURL("/api/v1/relay/" + url.PathEscape(relayID)))
+}
+
+func (c *APIClient) relayURL(path string) string {
+	base := strings.TrimRight(c.BaseURL, "/")
+	switch {
+	case strings.HasPrefix(base, "https://"):
+		base = "wss://" + strings.TrimPrefix(base, "https://")
+	case strings.HasPrefix(base, "http://"):
+		base = "ws://" + strings.TrimPrefix(base, "http://")
+	}
+	return base + path
+}
+
+func dialRelay(endpoint string) (*websocket.Conn, error) {
+	dialer := websocket.Dialer{HandshakeTimeout: 30 * time.Second}
+	conn, resp, err := dialer.Dial(endpoint, nil)
+	if err != nil {
+		if resp != nil {
+			defer resp.Body.Close()
+			msg, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode == http.StatusNotFound {
+				return nil, fmt.Errorf("relay not found; it may have expired or someone else already joined it")
+			}
+			return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+		}
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	return conn, nil
+}
@@ -3,6 +3,7 @@ package crypto
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
@@ -10,6 +11,17 @@ import (
 	"io"
 )
 
+// Algorithm identifiers are recorded with each drop (and inside its manifest)
+// so pull knows how the drop was sealed and what it can verify.
+const (
+	// AlgConvergent derives the key from the file and each nonce from its chunk.
+	// Identical files produce identical ciphertext, so the server can deduplicate.
+	AlgConvergent = "v1-aes-gcm"
+	// AlgPrivate uses a random key and random nonces. Nobody can confirm a guessed
+	// plaintext against the drop, at the cost of deduplication.
+	AlgPrivate = "v1-aes-gcm-private"
+)
+
 // GenerateKey creates a cryptographically secure random 32-byte (256-bit) key.
 // It returns the raw bytes and a Base64 encoded string to put in the URL.
 func GenerateKey() ([]byte, string, error) {
@@ -87,4 +99,60 @@ func Decrypt(key, ciphertext []byte) ([]byte, error) {
 	}
 
 	return plaintext, nil
-}
\ No newline at end of file
+}
+
+// sealWithAD is Encrypt with additional authenticated data. The nonce is an
+// HMAC of the AD and plaintext under the key: deterministic, never colliding
+// with a chunk nonce, and (unlike a bare hash) useless for confirming a guessed
+// plaintext to anyone who does not hold the key.
+func sealWithAD(key, plaintext, ad []byte) ([]byte, error) {
+	return sealAEAD(newAESGCM, key, plaintext, ad)
+}
+
+// openWithAD reverses sealWithAD.
+func openWithAD(key, ciphertext, ad []byte) ([]byte, error) {
+	return openAEAD(newAESGCM, key, ciphertext, ad)
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealAEAD is sealWithAD for any cipher in the suite registry.
+func sealAEAD(newAEAD func([]byte) (cipher.AEAD, error), key, plaintext, ad []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(ad)
+	mac.Write(plaintext)
+	nonce := mac.Sum(nil)[:aead.NonceSize()]
+
+	return aead.Seal(nonce, nonce, plaintext, ad), nil
+}
+
+// openAEAD reverses sealAEAD.
+func openAEAD(newAEAD func([]byte) (cipher.AEAD, error), key, ciphertext, ad []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, actualCiphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
+	plaintext, err := aead.Open(nil, nonce, actualCiphertext, ad)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed (wrong key or corrupted data): %w", err)
+	}
+	return plaintext, nil
+}
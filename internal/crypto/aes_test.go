@@ -2,6 +2,8 @@ package crypto
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"testing"
 )
 
@@ -61,4 +63,129 @@ func TestTamperedDataFails(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected decryption to fail on tampered data, but it succeeded!")
 	}
-}
\ No newline at end of file
+}
+
+func TestPrivateSuiteIsNotConvergent(t *testing.T) {
+	key, _, _ := GenerateKey()
+	plaintext := []byte("Sensitive Info")
+	suite, _ := LookupSuite(AlgPrivate)
+
+	first, err := suite.Encrypt(key, plaintext)
+	if err != nil {
+		t.Fatalf("Encryption failed: %v", err)
+	}
+	second, _ := suite.Encrypt(key, plaintext)
+	if bytes.Equal(first, second) {
+		t.Errorf("Random-nonce encryption produced identical ciphertexts")
+	}
+
+	for _, ciphertext := range [][]byte{first, second} {
+		decrypted, err := suite.Decrypt(key, ciphertext)
+		if err != nil || !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("Decrypt failed on random-nonce ciphertext: %v", err)
+		}
+	}
+}
+
+// Wire format vectors for v1 chunks and URL keys. Drops already in the wild
+// depend on these; if one fails, the change breaks existing URLs.
+const (
+	vectorKeyHex = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	vectorKeyURL = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+	vectorPlain  = "codedrop known answer"
+
+	// nonce (12) = SHA-256(plaintext)[:12] || AES-256-GCM ciphertext || tag (16)
+	vectorNonce  = "14ae878a5f723403ba929e2b"
+	vectorSealed = "8747f15188249bb3d6a2273b35e5a2abb404510db1" // Same length as the plaintext
+	vectorTag    = "0f5b929933a490eb71270f397009af3e"
+)
+
+func TestWireFormatVectors(t *testing.T) {
+	key, _ := hex.DecodeString(vectorKeyHex)
+
+	// URL keys are padded, URL-safe Base64 of the raw 32 bytes
+	decoded, err := DecodeKey(vectorKeyURL)
+	if err != nil || !bytes.Equal(decoded, key) {
+		t.Fatalf("DecodeKey(%q) = %x, %v", vectorKeyURL, decoded, err)
+	}
+	if _, err := DecodeKey("-__7__v_-__7__v_-__7__v_-__7__v_-__7__v_-_8="); err != nil {
+		t.Errorf("URL-safe alphabet ('-', '_') must be accepted: %v", err)
+	}
+
+	// The nonce is the truncated hash of the plaintext chunk
+	hash := sha256.Sum256([]byte(vectorPlain))
+	if got := hex.EncodeToString(hash[:12]); got != vectorNonce {
+		t.Fatalf("Nonce derivation changed: %s", got)
+	}
+
+	ciphertext, err := Encrypt(key, []byte(vectorPlain))
+	if err != nil {
+		t.Fatalf("Encryption failed: %v", err)
+	}
+	if got := hex.EncodeToString(ciphertext); got != vectorNonce+vectorSealed+vectorTag {
+		t.Errorf("Chunk layout changed:\n got  %s\n want %s", got, vectorNonce+vectorSealed+vectorTag)
+	}
+	if len(ciphertext) != 12+len(vectorPlain)+16 {
+		t.Errorf("Expected nonce + plaintext + tag, got %d bytes", len(ciphertext))
+	}
+
+	plaintext, err := Decrypt(key, ciphertext)
+	if err != nil || string(plaintext) != vectorPlain {
+		t.Errorf("Failed to decrypt vector: %v", err)
+	}
+}
+
+func FuzzDecrypt(f *testing.F) {
+	key, _ := hex.DecodeString(vectorKeyHex)
+	sealed, _ := hex.DecodeString(vectorNonce + vectorSealed + vectorTag)
+	f.Add(key, sealed)
+	f.Add(key, []byte{})
+	f.Add(key[:16], sealed[:12])
+
+	f.Fuzz(func(t *testing.T, key, ciphertext []byte) {
+		// Arbitrary input must fail cleanly, never panic, in every suite
+		plaintext, err := Decrypt(key, ciphertext)
+		if err == nil && len(plaintext) != len(ciphertext)-12-16 {
+			t.Errorf("Decrypt returned %d bytes from %d", len(plaintext), len(ciphertext))
+		}
+		for _, suite := range suites {
+			suite.Decrypt(key, ciphertext)
+		}
+	})
+}
+
+func FuzzEncryptRoundTrip(f *testing.F) {
+	f.Add([]byte(vectorPlain))
+	f.Add([]byte{})
+
+	key, _ := hex.DecodeString(vectorKeyHex)
+	f.Fuzz(func(t *testing.T, plaintext []byte) {
+		for _, suite := range suites {
+			ciphertext, err := suite.Encrypt(key, plaintext)
+			if err != nil {
+				t.Fatalf("%s: encryption failed: %v", suite.ID, err)
+			}
+			got, err := suite.Decrypt(key, ciphertext)
+			if err != nil || !bytes.Equal(got, plaintext) {
+				t.Fatalf("%s: round trip failed: %v", suite.ID, err)
+			}
+		}
+	})
+}
+
+func FuzzDecodeKey(f *testing.F) {
+	f.Add(vectorKeyURL)
+	f.Add("")
+	f.Add("AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8")
+	f.Add("not base64!")
+
+	f.Fuzz(func(t *testing.T, encoded string) {
+		key, err := DecodeKey(encoded)
+		if err != nil {
+			return
+		}
+		if len(key) != 32 {
+			t.Fatalf("DecodeKey accepted a %d byte key", len(key))
+		}
+	})
+}
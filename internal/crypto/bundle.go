@@ -0,0 +1,104 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// BundleManifestVersion marks a manifest that describes several files
+// (push a b c). Clients that only know ManifestVersion reject it instead of
+// writing the entries out as one file.
+const BundleManifestVersion = 2
+
+// BundleMimeType is the MIME type recorded in a bundle's FileMetadata.
+const BundleMimeType = "application/x-codedrop-bundle"
+
+// bundleHashLabel separates a bundle hash from any other SHA-256 in the protocol.
+var bundleHashLabel = []byte("codedrop-bundle-v1")
+
+// BundleEntry is one file in a bundle. Every entry starts on a chunk boundary
+// and owns chunks [FirstChunk, FirstChunk+ChunkCount); padding, if any, is
+// appended to the last entry.
+type BundleEntry struct {
+	Name       string    `json:"name"`
+	MimeType   string    `json:"mime_type,omitempty"`
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"mod_time,omitzero"`
+	FileHash   string    `json:"file_hash"` // SHA-256 of this entry's plaintext
+	FirstChunk int       `json:"first_chunk"`
+	ChunkCount int       `json:"chunk_count"`
+}
+
+// BundleHash is the content hash of a bundle: SHA-256 over every entry's name
+// and file hash, in order. It takes the place of the file hash for convergent
+// and team keys and in the manifest's FileHash.
+func BundleHash(entries []BundleEntry) ([]byte, error) {
+	h := sha256.New()
+	h.Write(bundleHashLabel)
+	for _, e := range entries {
+		fileHash, err := hex.DecodeString(e.FileHash)
+		if err != nil || len(fileHash) != sha256.Size {
+			return nil, fmt.Errorf("invalid file hash for %q", e.Name)
+		}
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(e.Name)))
+		h.Write(length[:])
+		h.Write([]byte(e.Name))
+		h.Write(fileHash)
+	}
+	return h.Sum(nil), nil
+}
+
+// Entry returns the bundle entry with the given name, or nil.
+func (m *Manifest) Entry(name string) *BundleEntry {
+	for i := range m.Entries {
+		if m.Entries[i].Name == name {
+			return &m.Entries[i]
+		}
+	}
+	return nil
+}
+
+// checkEntries makes sure a bundle's entries tile its chunks exactly, add up to
+// its size and hash to its FileHash. Single-file manifests must have none.
+func (m *Manifest) checkEntries() error {
+	if m.Version == ManifestVersion {
+		if len(m.Entries) > 0 {
+			return fmt.Errorf("version %d manifest lists bundle entries", m.Version)
+		}
+		return nil
+	}
+	if len(m.Entries) == 0 {
+		return fmt.Errorf("bundle manifest lists no entries")
+	}
+
+	names := make(map[string]bool)
+	next := 0
+	var size int64
+	for _, e := range m.Entries {
+		if e.Name == "" || names[e.Name] {
+			return fmt.Errorf("bundle entry name %q is empty or repeated", e.Name)
+		}
+		names[e.Name] = true
+		if e.FirstChunk != next || e.ChunkCount < 0 || e.Size < 0 {
+			return fmt.Errorf("bundle entry %q has an invalid chunk range", e.Name)
+		}
+		next += e.ChunkCount
+		size += e.Size
+	}
+	if next != m.ChunkCount || size != m.Size {
+		return fmt.Errorf("bundle entries do not add up to the manifest's chunks and size")
+	}
+
+	bundleHash, err := BundleHash(m.Entries)
+	if err != nil {
+		return err
+	}
+	if hex.EncodeToString(bundleHash) != m.FileHash {
+		return fmt.Errorf("bundle entries do not match the manifest's file hash")
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func testBundle(t *testing.T, key []byte) *Manifest {
+	files := []struct {
+		name   string
+		chunks [][]byte
+	}{
+		{"app.bin", [][]byte{[]byte("binary part 1"), []byte("binary part 2")}},
+		{"SHA256SUMS", [][]byte{[]byte("checksums")}},
+	}
+
+	m := &Manifest{Version: BundleManifestVersion}
+	for _, f := range files {
+		entry := BundleEntry{Name: f.name, FirstChunk: m.ChunkCount, ChunkCount: len(f.chunks)}
+		whole := sha256.New()
+		for _, chunk := range f.chunks {
+			ciphertext, err := Encrypt(key, chunk)
+			if err != nil {
+				t.Fatalf("Encryption failed: %v", err)
+			}
+			hash := sha256.Sum256(ciphertext)
+			m.ChunkHashes = append(m.ChunkHashes, hex.EncodeToString(hash[:]))
+			entry.Size += int64(len(chunk))
+			whole.Write(chunk)
+		}
+		entry.FileHash = hex.EncodeToString(whole.Sum(nil))
+		m.Entries = append(m.Entries, entry)
+		m.ChunkCount += entry.ChunkCount
+		m.Size += entry.Size
+	}
+
+	bundleHash, err := BundleHash(m.Entries)
+	if err != nil {
+		t.Fatalf("BundleHash failed: %v", err)
+	}
+	m.FileHash = hex.EncodeToString(bundleHash)
+	return m
+}
+
+func TestBundleManifestRoundTrip(t *testing.T) {
+	key, _, _ := GenerateKey()
+	m := testBundle(t, key)
+
+	sealed, err := SealManifest(testSuite, key, m)
+	if err != nil {
+		t.Fatalf("Failed to seal manifest: %v", err)
+	}
+	opened, err := OpenManifest(testSuite, key, sealed)
+	if err != nil {
+		t.Fatalf("Failed to open bundle manifest: %v", err)
+	}
+	entry := opened.Entry("SHA256SUMS")
+	if len(opened.Entries) != 2 || entry == nil || entry.FirstChunk != 2 || entry.ChunkCount != 1 {
+		t.Errorf("Opened bundle does not match original: %+v", opened.Entries)
+	}
+	if opened.Entry("missing") != nil {
+		t.Errorf("Expected no entry for an unknown name")
+	}
+}
+
+func TestBundleManifestRejectsInconsistentEntries(t *testing.T) {
+	key, _, _ := GenerateKey()
+
+	tests := map[string]func(m *Manifest){
+		"overlapping range": func(m *Manifest) { m.Entries[1].FirstChunk = 1 },
+		"uncovered chunk":   func(m *Manifest) { m.Entries[1].ChunkCount = 0 },
+		"wrong size":        func(m *Manifest) { m.Entries[0].Size++ },
+		"renamed entry":     func(m *Manifest) { m.Entries[0].Name = "evil.bin" },
+		"repeated name":     func(m *Manifest) { m.Entries[1].Name = m.Entries[0].Name },
+		"no entries":        func(m *Manifest) { m.Entries = nil },
+		"entries in v1":     func(m *Manifest) { m.Version = ManifestVersion },
+	}
+	for name, tamper := range tests {
+		m := testBundle(t, key)
+		tamper(m)
+		sealed, _ := SealManifest(testSuite, key, m)
+		if _, err := OpenManifest(testSuite, key, sealed); err == nil {
+			t.Errorf("%s: expected the manifest to be rejected", name)
+		}
+	}
+}
+
+func TestBundleHashBindsNamesAndOrder(t *testing.T) {
+	a := BundleEntry{Name: "a", FileHash: hex.EncodeToString(make([]byte, 32))}
+	b := BundleEntry{Name: "b", FileHash: a.FileHash}
+
+	ab, _ := BundleHash([]BundleEntry{a, b})
+	ba, _ := BundleHash([]BundleEntry{b, a})
+	if bytes.Equal(ab, ba) {
+		t.Errorf("Expected the bundle hash to depend on entry order")
+	}
+
+	// Length prefixes keep "a"+"b..." from colliding with "ab"+"..."
+	joined := BundleEntry{Name: "ab", FileHash: a.FileHash}
+	single, _ := BundleHash([]BundleEntry{joined})
+	if bytes.Equal(ab, single) {
+		t.Errorf("Expected different entry lists to hash differently")
+	}
+
+	if _, err := BundleHash([]BundleEntry{{Name: "x", FileHash: "not hex"}}); err == nil {
+		t.Errorf("Expected an invalid file hash to be rejected")
+	}
+}
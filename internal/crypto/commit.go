@@ -0,0 +1,56 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+)
+
+// Key commitment. AES-GCM and (X)ChaCha20-Poly1305 are not key-committing:
+// a crafted ciphertext can authenticate under two different keys, so a
+// swapped URL could open to a different, attacker-chosen file. Version 2
+// suites prepend HMAC-SHA256(key, context || nonce) to every ciphertext and
+// check it before decrypting. Finding two keys with the same commitment takes
+// a SHA-256 collision.
+//
+// Layout: commitment (32 bytes) || nonce || AEAD ciphertext and tag.
+
+// Version 2 (key-committing) algorithm identifiers. New drops use these.
+const (
+	AlgV2Convergent        = "v2-aes-gcm"
+	AlgV2Private           = "v2-aes-gcm-private"
+	AlgV2Team              = "v2-aes-gcm-team"
+	AlgV2XChaChaConvergent = "v2-xchacha20-poly1305"
+	AlgV2XChaChaPrivate    = "v2-xchacha20-poly1305-private"
+	AlgV2XChaChaTeam       = "v2-xchacha20-poly1305-team"
+)
+
+const commitmentSize = sha256.Size
+
+var commitmentContext = []byte("codedrop-key-commitment-v2")
+
+func keyCommitment(key, nonce []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(commitmentContext)
+	mac.Write(nonce)
+	return mac.Sum(nil)
+}
+
+// addCommitment prepends the commitment to a sealed nonce || ciphertext.
+func addCommitment(key, sealed []byte, nonceSize int) []byte {
+	out := make([]byte, 0, commitmentSize+len(sealed))
+	out = append(out, keyCommitment(key, sealed[:nonceSize])...)
+	return append(out, sealed...)
+}
+
+// checkCommitment verifies and strips the commitment, returning nonce || ciphertext.
+func checkCommitment(key, ciphertext []byte, nonceSize int) ([]byte, error) {
+	if len(ciphertext) < commitmentSize+nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	sealed := ciphertext[commitmentSize:]
+	if !hmac.Equal(ciphertext[:commitmentSize], keyCommitment(key, sealed[:nonceSize])) {
+		return nil, fmt.Errorf("decryption failed (wrong key or corrupted data): key commitment mismatch")
+	}
+	return sealed, nil
+}
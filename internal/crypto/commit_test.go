@@ -0,0 +1,142 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"testing"
+)
+
+// gcmCollision builds a one-block AES-GCM ciphertext (nonce || C || tag) that
+// authenticates under both k1 and k2, the "invisible salamanders" attack.
+// With one block, tag = C*H^2 ^ L*H ^ E_K(J0), which is linear in C, so C can
+// be solved for directly once both keys are known.
+func gcmCollision(t *testing.T, k1, k2, nonce []byte) []byte {
+	t.Helper()
+	var lengths [16]byte
+	binary.BigEndian.PutUint64(lengths[8:], 16*8) // no AD, one block of ciphertext
+
+	hashKey := func(key []byte) (h, mask [16]byte) {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var j0 [16]byte
+		copy(j0[:], nonce)
+		j0[15] = 1
+		block.Encrypt(h[:], make([]byte, 16))
+		block.Encrypt(mask[:], j0[:])
+		return h, mask
+	}
+	h1, e1 := hashKey(k1)
+	h2, e2 := hashKey(k2)
+
+	// C * (H1^2 ^ H2^2) = L*H1 ^ E1 ^ L*H2 ^ E2
+	coeff := xor128(gcmMul(h1, h1), gcmMul(h2, h2))
+	rhs := xor128(xor128(gcmMul(lengths, h1), e1), xor128(gcmMul(lengths, h2), e2))
+	c := gcmMul(rhs, gcmInv(coeff))
+	tag := xor128(xor128(gcmMul(c, gcmMul(h1, h1)), gcmMul(lengths, h1)), e1)
+
+	out := append(append([]byte{}, nonce...), c[:]...)
+	return append(out, tag[:]...)
+}
+
+func xor128(a, b [16]byte) [16]byte {
+	for i := range a {
+		a[i] ^= b[i]
+	}
+	return a
+}
+
+// gcmMul multiplies in GF(2^128) with GCM's bit order (NIST SP 800-38D, Algorithm 1).
+func gcmMul(x, y [16]byte) [16]byte {
+	var z [16]byte
+	v := y
+	for i := 0; i < 128; i++ {
+		if x[i/8]>>(7-i%8)&1 == 1 {
+			z = xor128(z, v)
+		}
+		lsb := v[15] & 1
+		for j := 15; j > 0; j-- {
+			v[j] = v[j]>>1 | v[j-1]<<7
+		}
+		v[0] >>= 1
+		if lsb == 1 {
+			v[0] ^= 0xe1
+		}
+	}
+	return z
+}
+
+// gcmInv returns a^(2^128-2) = a^-1.
+func gcmInv(a [16]byte) [16]byte {
+	result := [16]byte{0x80} // The field's 1
+	square := a
+	for i := 1; i < 128; i++ {
+		square = gcmMul(square, square)
+		result = gcmMul(result, square)
+	}
+	return result
+}
+
+func TestGCMIsNotKeyCommitting(t *testing.T) {
+	k1 := bytes.Repeat([]byte{1}, 32)
+	k2 := bytes.Repeat([]byte{2}, 32)
+	forged := gcmCollision(t, k1, k2, make([]byte, 12))
+
+	// The version 1 format accepts the same ciphertext under both keys. This is
+	// the attack the committing format exists to stop.
+	p1, err1 := Decrypt(k1, forged)
+	p2, err2 := Decrypt(k2, forged)
+	if err1 != nil || err2 != nil {
+		t.Fatalf("Collision construction failed: %v / %v", err1, err2)
+	}
+	if bytes.Equal(p1, p2) {
+		t.Fatalf("Expected the two keys to yield different plaintexts")
+	}
+}
+
+func TestCommittingSuiteRejectsMultiKeyCiphertext(t *testing.T) {
+	k1 := bytes.Repeat([]byte{1}, 32)
+	k2 := bytes.Repeat([]byte{2}, 32)
+	forged := gcmCollision(t, k1, k2, make([]byte, 12))
+	suite, _ := LookupSuite(AlgV2Private)
+
+	// The attacker can only commit to one key; the other is refused
+	committed := addCommitment(k1, forged, 12)
+	if _, err := suite.Decrypt(k1, committed); err != nil {
+		t.Fatalf("Expected the committed key to decrypt: %v", err)
+	}
+	if _, err := suite.Decrypt(k2, committed); err == nil {
+		t.Errorf("Expected the second key to be rejected by the commitment")
+	}
+
+	// Stripping the commitment does not help either
+	if _, err := suite.Decrypt(k2, forged); err == nil {
+		t.Errorf("Expected an uncommitted ciphertext to be rejected")
+	}
+}
+
+func TestCommittingEnvelopeRejectsWrongKey(t *testing.T) {
+	key, _, _ := GenerateKey()
+	otherKey, _, _ := GenerateKey()
+
+	for _, alg := range []string{AlgV2Convergent, AlgV2XChaChaPrivate} {
+		suite, _ := LookupSuite(alg)
+		sealed, err := SealManifest(suite, key, testManifest(t, key, []byte("data")))
+		if err != nil {
+			t.Fatalf("%s: failed to seal manifest: %v", alg, err)
+		}
+		if _, err := OpenManifest(suite, key, sealed); err != nil {
+			t.Errorf("%s: failed to open manifest: %v", alg, err)
+		}
+		if _, err := OpenManifest(suite, otherKey, sealed); err == nil {
+			t.Errorf("%s: expected the wrong key to be rejected", alg)
+		}
+
+		// New drops get the committing suite
+		if selected, _ := SelectSuite(suite.Cipher, suite.KeyMode); selected != suite {
+			t.Errorf("%s: push should select the committing suite, got %s", alg, selected.ID)
+		}
+	}
+}
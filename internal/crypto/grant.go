@@ -0,0 +1,66 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Access grants (push --recipients) give each person their own link to one
+// drop. Every grant has a random grant key, carried in its URL as #g=...; the
+// drop key is wrapped under it and stored on the server with the grant. The
+// server counts and revokes downloads per grant ID, which is derived from the
+// grant key, so the URL needs nothing else and the server never sees the key.
+
+// GrantKeySize is the size of a grant key in bytes.
+const GrantKeySize = 32
+
+// ErrWrongGrantKey is returned when a grant's wrapped key does not open.
+var ErrWrongGrantKey = errors.New("grant key does not open this grant")
+
+var grantIDLabel = []byte("codedrop-grant-id-v1")
+
+// NewGrantKey generates a random grant key.
+func NewGrantKey() ([]byte, error) {
+	key := make([]byte, GrantKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("failed to generate grant key: %w", err)
+	}
+	return key, nil
+}
+
+// GrantID is the public identifier of a grant: the first 128 bits of a
+// labelled SHA-256 of its key, in hex.
+func GrantID(grantKey []byte) string {
+	h := sha256.New()
+	h.Write(grantIDLabel)
+	h.Write(grantKey)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// WrapGrantKey encrypts the drop key under a grant key, bound to the grant's ID.
+func WrapGrantKey(grantKey, dropKey []byte) ([]byte, error) {
+	if len(grantKey) != GrantKeySize {
+		return nil, fmt.Errorf("grant key must be %d bytes", GrantKeySize)
+	}
+	return sealKey(grantKey, dropKey, grantAD(GrantID(grantKey)))
+}
+
+// UnwrapGrantKey reverses WrapGrantKey.
+func UnwrapGrantKey(grantKey, wrapped []byte) ([]byte, error) {
+	if len(grantKey) != GrantKeySize {
+		return nil, fmt.Errorf("grant key must be %d bytes", GrantKeySize)
+	}
+	dropKey, err := openKey(grantKey, wrapped, grantAD(GrantID(grantKey)))
+	if errors.Is(err, errKeyNotOpened) {
+		return nil, ErrWrongGrantKey
+	}
+	return dropKey, err
+}
+
+func grantAD(grantID string) []byte {
+	return []byte("codedrop-grant-wrap-v1|" + grantID)
+}
@@ -0,0 +1,55 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestGrantKeyRoundTrip(t *testing.T) {
+	dropKey, _, _ := GenerateKey()
+	grantKey, err := NewGrantKey()
+	if err != nil {
+		t.Fatalf("Failed to generate grant key: %v", err)
+	}
+
+	wrapped, err := WrapGrantKey(grantKey, dropKey)
+	if err != nil {
+		t.Fatalf("Failed to wrap drop key: %v", err)
+	}
+	unwrapped, err := UnwrapGrantKey(grantKey, wrapped)
+	if err != nil {
+		t.Fatalf("Failed to unwrap drop key: %v", err)
+	}
+	if !bytes.Equal(unwrapped, dropKey) {
+		t.Errorf("Unwrapped key does not match the drop key")
+	}
+
+	// Another grant's key must not open it
+	otherKey, _ := NewGrantKey()
+	if _, err := UnwrapGrantKey(otherKey, wrapped); !errors.Is(err, ErrWrongGrantKey) {
+		t.Errorf("Expected ErrWrongGrantKey, got %v", err)
+	}
+}
+
+func TestGrantIDDependsOnKey(t *testing.T) {
+	a, _ := NewGrantKey()
+	b, _ := NewGrantKey()
+	if GrantID(a) == GrantID(b) {
+		t.Errorf("Expected different grant keys to have different IDs")
+	}
+	if GrantID(a) != GrantID(a) || len(GrantID(a)) != 32 {
+		t.Errorf("Expected a stable 32-character grant ID, got %q", GrantID(a))
+	}
+}
+
+func TestGrantKeyIsNotAPassphraseWrap(t *testing.T) {
+	dropKey, _, _ := GenerateKey()
+	grantKey, _ := NewGrantKey()
+
+	// A passphrase-wrapped key and a grant-wrapped key are bound to different contexts
+	wrapped, _ := WrapKey(grantKey, dropKey, GrantID(grantKey))
+	if _, err := UnwrapGrantKey(grantKey, wrapped); err == nil {
+		t.Errorf("Expected a passphrase wrap to be rejected as a grant wrap")
+	}
+}
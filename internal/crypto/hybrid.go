@@ -0,0 +1,177 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/mlkem"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// Hybrid post-quantum wrapping. The drop key is wrapped under a secret that
+// combines ML-KEM-768 and X25519, so it stays confidential unless BOTH are
+// broken. This protects long-lived secrets against "harvest now, decrypt
+// later" while keeping X25519's well-understood security today.
+//
+// These keys are CodeDrop's own (cdpq1... / CODEDROP-PQ-SECRET-KEY-1...); they
+// are not interchangeable with age's post-quantum keys.
+
+// StanzaMLKEM768X25519 is the hybrid stanza type. Classical identities never
+// match it, and hybrid identities never match classical stanzas.
+const StanzaMLKEM768X25519 = "mlkem768x25519-v1"
+
+const (
+	hybridRecipientHRP = "cdpq"
+	hybridIdentityHRP  = "CODEDROP-PQ-SECRET-KEY-"
+	hybridSeedSize     = 32
+)
+
+// HybridRecipient is an ML-KEM-768 encapsulation key paired with an X25519 key.
+type HybridRecipient struct {
+	ek *mlkem.EncapsulationKey768
+	x  *ecdh.PublicKey
+}
+
+// HybridIdentity is a seed from which both private keys are derived.
+type HybridIdentity struct {
+	seed []byte
+	dk   *mlkem.DecapsulationKey768
+	x    *ecdh.PrivateKey
+}
+
+// GenerateHybridIdentity creates a fresh identity for `codedrop keygen --pq`.
+func GenerateHybridIdentity() (*HybridIdentity, error) {
+	seed := make([]byte, hybridSeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return newHybridIdentity(seed)
+}
+
+// newHybridIdentity expands a seed into the ML-KEM and X25519 private keys.
+func newHybridIdentity(seed []byte) (*HybridIdentity, error) {
+	if len(seed) != hybridSeedSize {
+		return nil, fmt.Errorf("invalid seed size: %d", len(seed))
+	}
+	expanded, err := hkdf.Key(sha256.New, seed, nil, "codedrop-pq-identity-v1", mlkem.SeedSize+32)
+	if err != nil {
+		return nil, err
+	}
+	dk, err := mlkem.NewDecapsulationKey768(expanded[:mlkem.SeedSize])
+	if err != nil {
+		return nil, err
+	}
+	x, err := ecdh.X25519().NewPrivateKey(expanded[mlkem.SeedSize:])
+	if err != nil {
+		return nil, err
+	}
+	return &HybridIdentity{seed: seed, dk: dk, x: x}, nil
+}
+
+func parseHybridRecipient(s string) (*HybridRecipient, error) {
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed recipient: %w", err)
+	}
+	if hrp != hybridRecipientHRP || len(data) != mlkem.EncapsulationKeySize768+32 {
+		return nil, fmt.Errorf("malformed recipient: not a %s key", StanzaMLKEM768X25519)
+	}
+	ek, err := mlkem.NewEncapsulationKey768(data[:mlkem.EncapsulationKeySize768])
+	if err != nil {
+		return nil, fmt.Errorf("malformed recipient: %w", err)
+	}
+	x, err := ecdh.X25519().NewPublicKey(data[mlkem.EncapsulationKeySize768:])
+	if err != nil {
+		return nil, fmt.Errorf("malformed recipient: %w", err)
+	}
+	return &HybridRecipient{ek: ek, x: x}, nil
+}
+
+func parseHybridIdentity(s string) (*HybridIdentity, error) {
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed secret key: %w", err)
+	}
+	if hrp != strings.ToLower(hybridIdentityHRP) {
+		return nil, fmt.Errorf("malformed secret key: unexpected prefix %q", hrp)
+	}
+	return newHybridIdentity(data)
+}
+
+// Wrap encapsulates to the ML-KEM key, runs ephemeral-static X25519, and seals
+// the drop key under a key derived from both shared secrets. Args are the
+// ML-KEM ciphertext and the ephemeral X25519 key.
+func (r *HybridRecipient) Wrap(dropKey []byte) (*Stanza, error) {
+	kemShared, kemCiphertext := r.ek.Encapsulate()
+
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	xShared, err := ephemeral.ECDH(r.x)
+	if err != nil {
+		return nil, err
+	}
+	epk := ephemeral.PublicKey().Bytes()
+
+	body, err := sealWrappedKey(hybridSecret(kemShared, xShared), hybridTranscript(kemCiphertext, epk), r.bytes(), StanzaMLKEM768X25519, dropKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Stanza{Type: StanzaMLKEM768X25519, Args: [][]byte{kemCiphertext, epk}, Body: body}, nil
+}
+
+func (r *HybridRecipient) String() string {
+	s, _ := bech32Encode(hybridRecipientHRP, r.bytes())
+	return s
+}
+
+func (r *HybridRecipient) bytes() []byte {
+	return append(r.ek.Bytes(), r.x.Bytes()...)
+}
+
+func (i *HybridIdentity) Unwrap(s *Stanza) ([]byte, error) {
+	if s.Type != StanzaMLKEM768X25519 || len(s.Args) != 2 || len(s.Args[0]) != mlkem.CiphertextSize768 {
+		return nil, ErrIncorrectIdentity
+	}
+	kemCiphertext, epk := s.Args[0], s.Args[1]
+
+	// ML-KEM decapsulation never fails on a well-sized ciphertext (implicit
+	// rejection); a stanza for someone else just yields a key that won't open.
+	kemShared, err := i.dk.Decapsulate(kemCiphertext)
+	if err != nil {
+		return nil, ErrIncorrectIdentity
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(epk)
+	if err != nil {
+		return nil, ErrIncorrectIdentity
+	}
+	xShared, err := i.x.ECDH(ephemeral)
+	if err != nil {
+		return nil, ErrIncorrectIdentity
+	}
+
+	recipient := i.Recipient().(*HybridRecipient)
+	return openWrappedKey(hybridSecret(kemShared, xShared), hybridTranscript(kemCiphertext, epk), recipient.bytes(), StanzaMLKEM768X25519, s.Body)
+}
+
+func (i *HybridIdentity) Recipient() Recipient {
+	return &HybridRecipient{ek: i.dk.EncapsulationKey(), x: i.x.PublicKey()}
+}
+
+func (i *HybridIdentity) String() string {
+	s, _ := bech32Encode(hybridIdentityHRP, i.seed)
+	return strings.ToUpper(s)
+}
+
+// hybridSecret concatenates both shared secrets; HKDF in wrapAEAD combines them.
+func hybridSecret(kemShared, xShared []byte) []byte {
+	return append(append([]byte{}, kemShared...), xShared...)
+}
+
+// hybridTranscript binds both ciphertexts into the key derivation.
+func hybridTranscript(kemCiphertext, epk []byte) []byte {
+	return append(append([]byte{}, kemCiphertext...), epk...)
+}
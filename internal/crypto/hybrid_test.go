@@ -0,0 +1,111 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestHybridRecipientRoundTrip(t *testing.T) {
+	id, err := GenerateHybridIdentity()
+	if err != nil {
+		t.Fatalf("Failed to generate identity: %v", err)
+	}
+
+	recipient, err := ParseRecipient(id.Recipient().String())
+	if err != nil {
+		t.Fatalf("Failed to parse hybrid recipient: %v", err)
+	}
+	ids, err := ParseIdentities([]byte(id.String()+"\n"), nil)
+	if err != nil {
+		t.Fatalf("Failed to parse hybrid identity: %v", err)
+	}
+
+	dropKey, _, _ := GenerateKey()
+	stanza, err := recipient.Wrap(dropKey)
+	if err != nil {
+		t.Fatalf("Failed to wrap: %v", err)
+	}
+	if stanza.Type != StanzaMLKEM768X25519 {
+		t.Errorf("Expected stanza type %s, got %s", StanzaMLKEM768X25519, stanza.Type)
+	}
+
+	unwrapped, err := UnwrapStanzas(ids, []*Stanza{stanza})
+	if err != nil || !bytes.Equal(unwrapped, dropKey) {
+		t.Fatalf("Failed to unwrap: %v", err)
+	}
+
+	// Another hybrid identity decapsulates to garbage and must not match
+	other, _ := GenerateHybridIdentity()
+	if _, err := other.Unwrap(stanza); !errors.Is(err, ErrIncorrectIdentity) {
+		t.Errorf("Expected ErrIncorrectIdentity, got %v", err)
+	}
+
+	// Tampering with either half of the key exchange is detected
+	for i := range stanza.Args {
+		stanza.Args[i][0] ^= 1
+		if _, err := id.Unwrap(stanza); !errors.Is(err, ErrIncorrectIdentity) {
+			t.Errorf("Expected tampered arg %d to be rejected, got %v", i, err)
+		}
+		stanza.Args[i][0] ^= 1
+	}
+}
+
+func TestHybridAndClassicalStanzasAreDistinct(t *testing.T) {
+	classical, _ := GenerateX25519Identity()
+	hybrid, _ := GenerateHybridIdentity()
+	dropKey, _, _ := GenerateKey()
+
+	classicalStanza, _ := classical.Recipient().Wrap(dropKey)
+	hybridStanza, _ := hybrid.Recipient().Wrap(dropKey)
+
+	if _, err := hybrid.Unwrap(classicalStanza); !errors.Is(err, ErrIncorrectIdentity) {
+		t.Errorf("Hybrid identity should not match a classical stanza, got %v", err)
+	}
+	if _, err := classical.Unwrap(hybridStanza); !errors.Is(err, ErrIncorrectIdentity) {
+		t.Errorf("Classical identity should not match a hybrid stanza, got %v", err)
+	}
+}
+
+func TestHybridDecapsulationVector(t *testing.T) {
+	data, err := os.ReadFile("testdata/mlkem768x25519-v1.json")
+	if err != nil {
+		t.Fatalf("Failed to read vector: %v", err)
+	}
+	var vector struct {
+		Identity        string          `json:"identity"`
+		RecipientSHA256 string          `json:"recipient_sha256"`
+		DropKey         string          `json:"drop_key"`
+		Stanzas         json.RawMessage `json:"stanzas"`
+	}
+	if err := json.Unmarshal(data, &vector); err != nil {
+		t.Fatalf("Failed to parse vector: %v", err)
+	}
+
+	ids, err := ParseIdentities([]byte(vector.Identity), nil)
+	if err != nil {
+		t.Fatalf("Failed to parse identity: %v", err)
+	}
+
+	// Key derivation from the seed is part of the format
+	recipientHash := sha256.Sum256([]byte(ids[0].Recipient().String()))
+	if got := hex.EncodeToString(recipientHash[:]); got != vector.RecipientSHA256 {
+		t.Errorf("Recipient hash %s, want %s", got, vector.RecipientSHA256)
+	}
+
+	stanzas, err := ParseStanzas(vector.Stanzas)
+	if err != nil {
+		t.Fatalf("Failed to parse stanzas: %v", err)
+	}
+	dropKey, err := UnwrapStanzas(ids, stanzas)
+	if err != nil {
+		t.Fatalf("Failed to unwrap vector: %v", err)
+	}
+	if got := hex.EncodeToString(dropKey); got != vector.DropKey {
+		t.Errorf("Unwrapped %s, want %s", got, vector.DropKey)
+	}
+}
@@ -0,0 +1,78 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// ManifestVersion is bumped whenever the manifest layout changes.
+const ManifestVersion = 1
+
+// manifestAD binds the manifest ciphertext to its purpose, so a chunk can
+// never be passed off as a manifest (or the other way round).
+var manifestAD = []byte("codedrop-manifest-v1")
+
+// Manifest is the authenticated description of a drop. It is encrypted under
+// the drop key and uploaded after the chunks, so the receiver can detect a
+// server that drops, reorders or swaps chunks.
+type Manifest struct {
+	Version     int           `json:"v"`
+	Algorithm   string        `json:"alg"`               // Authenticated copy of the drop's algorithm
+	KeyID       string        `json:"key_id,omitempty"`  // Team secret the key was derived from (AlgTeam)
+	Size        int64         `json:"size"`              // True plaintext length in bytes, excluding padding
+	Padding     string        `json:"padding,omitempty"` // Padding scheme appended after Size bytes
+	ChunkCount  int           `json:"chunk_count"`       // Number of chunks the sender uploaded
+	ChunkHashes []string      `json:"chunk_hashes"`      // SHA-256 of each *encrypted* chunk, in order
+	FileHash    string        `json:"file_hash"`         // SHA-256 of the whole plaintext, or BundleHash
+	Entries     []BundleEntry `json:"entries,omitempty"` // Files in a bundle (BundleManifestVersion)
+
+	Signature *ManifestSignature `json:"signature,omitempty"` // Optional sender signature
+}
+
+// SealManifest serializes and encrypts a manifest under the drop key.
+func SealManifest(suite *Suite, key []byte, m *Manifest) ([]byte, error) {
+	plaintext, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return suite.seal(key, plaintext, manifestAD)
+}
+
+// OpenManifest decrypts and authenticates a manifest. Any tampering or a wrong
+// key is reported as an error; the caller must not download anything then.
+func OpenManifest(suite *Suite, key, sealed []byte) (*Manifest, error) {
+	plaintext, err := suite.open(key, sealed, manifestAD)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(plaintext, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	if m.Version != ManifestVersion && m.Version != BundleManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	if m.ChunkCount != len(m.ChunkHashes) {
+		return nil, fmt.Errorf("manifest lists %d chunk hashes for %d chunks", len(m.ChunkHashes), m.ChunkCount)
+	}
+	if err := m.checkEntries(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// VerifyChunk checks that an encrypted chunk is exactly the one the sender
+// uploaded at this position.
+func (m *Manifest) VerifyChunk(index int, ciphertext []byte) error {
+	if index < 0 || index >= m.ChunkCount {
+		return fmt.Errorf("chunk %d is not part of the manifest", index)
+	}
+	hash := sha256.Sum256(ciphertext)
+	if hex.EncodeToString(hash[:]) != m.ChunkHashes[index] {
+		return fmt.Errorf("chunk %d does not match the manifest", index)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func testManifest(t *testing.T, key []byte, chunks ...[]byte) *Manifest {
+	m := &Manifest{Version: ManifestVersion, ChunkCount: len(chunks)}
+	whole := sha256.New()
+	for _, chunk := range chunks {
+		ciphertext, err := Encrypt(key, chunk)
+		if err != nil {
+			t.Fatalf("Encryption failed: %v", err)
+		}
+		hash := sha256.Sum256(ciphertext)
+		m.ChunkHashes = append(m.ChunkHashes, hex.EncodeToString(hash[:]))
+		m.Size += int64(len(chunk))
+		whole.Write(chunk)
+	}
+	m.FileHash = hex.EncodeToString(whole.Sum(nil))
+	return m
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	key, _, _ := GenerateKey()
+	m := testManifest(t, key, []byte("first chunk"), []byte("second chunk"))
+
+	sealed, err := SealManifest(testSuite, key, m)
+	if err != nil {
+		t.Fatalf("Failed to seal manifest: %v", err)
+	}
+
+	opened, err := OpenManifest(testSuite, key, sealed)
+	if err != nil {
+		t.Fatalf("Failed to open manifest: %v", err)
+	}
+	if opened.ChunkCount != 2 || opened.Size != m.Size || opened.FileHash != m.FileHash {
+		t.Errorf("Opened manifest does not match original: %+v", opened)
+	}
+
+	// Every chunk we produced must verify at its own position only
+	c0, _ := Encrypt(key, []byte("first chunk"))
+	c1, _ := Encrypt(key, []byte("second chunk"))
+	if err := opened.VerifyChunk(0, c0); err != nil {
+		t.Errorf("Chunk 0 should verify: %v", err)
+	}
+	if err := opened.VerifyChunk(1, c0); err == nil {
+		t.Errorf("Swapped chunk should not verify")
+	}
+	if err := opened.VerifyChunk(2, c1); err == nil {
+		t.Errorf("Chunk beyond the manifest should not verify")
+	}
+}
+
+func TestManifestRejectsTamperingAndWrongKey(t *testing.T) {
+	key, _, _ := GenerateKey()
+	otherKey, _, _ := GenerateKey()
+	sealed, _ := SealManifest(testSuite, key, testManifest(t, key, []byte("data")))
+
+	if _, err := OpenManifest(testSuite, otherKey, sealed); err == nil {
+		t.Errorf("Expected manifest to fail under the wrong key")
+	}
+
+	sealed[len(sealed)-1] ^= 0xFF
+	if _, err := OpenManifest(testSuite, key, sealed); err == nil {
+		t.Errorf("Expected tampered manifest to fail")
+	}
+}
+
+func TestChunkCannotPoseAsManifest(t *testing.T) {
+	key, _, _ := GenerateKey()
+
+	// A chunk whose plaintext happens to be a valid manifest is still rejected,
+	// because chunks are sealed without the manifest's associated data.
+	m := testManifest(t, key, []byte("data"))
+	sealed, _ := SealManifest(testSuite, key, m)
+	plaintext, _ := openWithAD(key, sealed, manifestAD)
+	chunk, _ := Encrypt(key, plaintext)
+
+	if _, err := OpenManifest(testSuite, key, chunk); err == nil {
+		t.Errorf("Expected a chunk ciphertext to be rejected as a manifest")
+	}
+}
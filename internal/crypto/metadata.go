@@ -0,0 +1,42 @@
+package crypto
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// metadataAD separates the metadata blob from chunks and the manifest.
+var metadataAD = []byte("codedrop-metadata-v1")
+
+// FileMetadata describes the uploaded file. It is encrypted under the drop key
+// before it leaves the sender, so the server only ever stores an opaque blob.
+type FileMetadata struct {
+	Name     string    `json:"name"`
+	MimeType string    `json:"mime_type,omitempty"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time,omitzero"`
+}
+
+// SealMetadata serializes and encrypts file metadata under the drop key.
+func SealMetadata(suite *Suite, key []byte, meta *FileMetadata) ([]byte, error) {
+	plaintext, err := json.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return suite.seal(key, plaintext, metadataAD)
+}
+
+// OpenMetadata decrypts and authenticates a metadata blob.
+func OpenMetadata(suite *Suite, key, sealed []byte) (*FileMetadata, error) {
+	plaintext, err := suite.open(key, sealed, metadataAD)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta FileMetadata
+	if err := json.Unmarshal(plaintext, &meta); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return &meta, nil
+}
@@ -0,0 +1,32 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMetadataRoundTrip(t *testing.T) {
+	key, _, _ := GenerateKey()
+	meta := &FileMetadata{Name: "prod-db-dump-customers.sql", MimeType: "application/sql", Size: 1234}
+
+	sealed, err := SealMetadata(testSuite, key, meta)
+	if err != nil {
+		t.Fatalf("Failed to seal metadata: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("customers")) {
+		t.Errorf("Sealed metadata leaks the file name")
+	}
+
+	opened, err := OpenMetadata(testSuite, key, sealed)
+	if err != nil {
+		t.Fatalf("Failed to open metadata: %v", err)
+	}
+	if *opened != *meta {
+		t.Errorf("Opened metadata does not match original: %+v", opened)
+	}
+
+	// Metadata must not be accepted where a manifest is expected
+	if _, err := OpenManifest(testSuite, key, sealed); err == nil {
+		t.Errorf("Expected metadata blob to be rejected as a manifest")
+	}
+}
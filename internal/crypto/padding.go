@@ -0,0 +1,61 @@
+package crypto
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// Padding schemes supported by `push --pad`. The true length is only ever
+// recorded inside the encrypted manifest; the server sees the padded size.
+const (
+	PadNone  = "none"
+	PadPadme = "padme" // Padmé: at most ~12% overhead, leaks O(log log n) bits of the size
+	PadPow2  = "pow2"  // Next power of two: up to 100% overhead, leaks O(log n) bits
+)
+
+// PaddedSize returns the length a file of the given size is padded to.
+func PaddedSize(scheme string, size int64) (int64, error) {
+	if size < 0 {
+		return 0, fmt.Errorf("invalid size %d", size)
+	}
+
+	switch scheme {
+	case PadNone, "":
+		return size, nil
+	case PadPadme:
+		return padme(size), nil
+	case PadPow2:
+		if size <= 1 {
+			return size, nil
+		}
+		return 1 << bits.Len64(uint64(size-1)), nil
+	default:
+		return 0, fmt.Errorf("unknown padding scheme %q (use %s, %s or %s)", scheme, PadNone, PadPadme, PadPow2)
+	}
+}
+
+// padme implements the Padmé scheme from "Reducing Metadata Leakage from
+// Encrypted Files and Communication with PURBs" (Nikitin et al., 2019):
+// keep the top bits of the length needed to encode its exponent, round up the rest.
+func padme(size int64) int64 {
+	if size < 2 {
+		return size
+	}
+	e := bits.Len64(uint64(size)) - 1 // floor(log2 L)
+	s := bits.Len64(uint64(e))        // floor(log2 E) + 1
+	mask := int64(1)<<(e-s) - 1
+	return (size + mask) &^ mask
+}
+
+// PadReader yields exactly size bytes from r followed by zero bytes up to paddedSize.
+func PadReader(r io.Reader, size, paddedSize int64) io.Reader {
+	return io.MultiReader(io.LimitReader(r, size), io.LimitReader(zeroReader{}, paddedSize-size))
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -0,0 +1,65 @@
+package crypto
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPaddedSize(t *testing.T) {
+	cases := []struct {
+		scheme string
+		size   int64
+		want   int64
+	}{
+		{PadNone, 12345, 12345},
+		{PadPadme, 0, 0},
+		{PadPadme, 1, 1},
+		{PadPadme, 7, 7},
+		{PadPadme, 9, 10},
+		{PadPadme, 1000000, 1015808},
+		{PadPow2, 0, 0},
+		{PadPow2, 5, 8},
+		{PadPow2, 4096, 4096},
+		{PadPow2, 4097, 8192},
+	}
+
+	for _, c := range cases {
+		got, err := PaddedSize(c.scheme, c.size)
+		if err != nil {
+			t.Fatalf("PaddedSize(%s, %d) failed: %v", c.scheme, c.size, err)
+		}
+		if got != c.want {
+			t.Errorf("PaddedSize(%s, %d) = %d, want %d", c.scheme, c.size, got, c.want)
+		}
+	}
+
+	if _, err := PaddedSize("bogus", 10); err == nil {
+		t.Errorf("Expected unknown scheme to be rejected")
+	}
+}
+
+func TestPadmeOverheadIsBounded(t *testing.T) {
+	// Padmé guarantees at most ~12% overhead, and padding never shrinks a file
+	for size := int64(1); size < 1<<22; size = size*3/2 + 1 {
+		padded := padme(size)
+		if padded < size {
+			t.Fatalf("padme(%d) = %d shrinks the file", size, padded)
+		}
+		if float64(padded-size)/float64(size) > 0.12 {
+			t.Errorf("padme(%d) = %d exceeds 12%% overhead", size, padded)
+		}
+	}
+}
+
+func TestPadReader(t *testing.T) {
+	// Extra bytes in the source beyond size must be ignored
+	padded, err := io.ReadAll(PadReader(strings.NewReader("hello world"), 5, 8))
+	if err != nil {
+		t.Fatalf("Failed to read padded stream: %v", err)
+	}
+	if !bytes.Equal(padded, []byte("hello\x00\x00\x00")) {
+		t.Errorf("Unexpected padded stream: %q", padded)
+	}
+}
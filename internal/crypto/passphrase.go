@@ -0,0 +1,153 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// ErrWrongPassphrase is returned when a wrapped key does not open, which
+// almost always means the passphrase was mistyped.
+var ErrWrongPassphrase = errors.New("incorrect passphrase")
+
+// Argon2id defaults, following the second recommended option of RFC 9106
+// (64 MiB, 3 passes, 4 lanes).
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024 // KiB
+	argonThreads = 4
+	saltSize     = 16
+)
+
+// Upper bounds for parameters read back from the server, so a malicious
+// server cannot make pull allocate unbounded memory or spin forever.
+const (
+	maxArgonTime   = 10
+	maxArgonMemory = 1024 * 1024 // 1 GiB in KiB
+)
+
+// KDFParams holds an Argon2id configuration together with its salt. It is
+// stored in the drop's encryption_salt column as
+// "argon2id$v=19$m=65536,t=3,p=4$<base64 salt>".
+type KDFParams struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+	Salt    []byte
+}
+
+// NewKDFParams returns the default Argon2id parameters with a fresh random salt.
+func NewKDFParams() (*KDFParams, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return &KDFParams{Time: argonTime, Memory: argonMemory, Threads: argonThreads, Salt: salt}, nil
+}
+
+// String encodes the parameters for the encryption_salt column.
+func (p *KDFParams) String() string {
+	return fmt.Sprintf("argon2id$v=%d$m=%d,t=%d,p=%d$%s",
+		argon2.Version, p.Memory, p.Time, p.Threads, base64.RawStdEncoding.EncodeToString(p.Salt))
+}
+
+// ParseKDFParams decodes a value produced by KDFParams.String.
+func ParseKDFParams(encoded string) (*KDFParams, error) {
+	var version int
+	var p KDFParams
+	var salt string
+	_, err := fmt.Sscanf(encoded, "argon2id$v=%d$m=%d,t=%d,p=%d$%s", &version, &p.Memory, &p.Time, &p.Threads, &salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid passphrase parameters %q: %w", encoded, err)
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+	if p.Time == 0 || p.Time > maxArgonTime || p.Memory == 0 || p.Memory > maxArgonMemory || p.Threads == 0 {
+		return nil, fmt.Errorf("passphrase parameters out of range: %q", encoded)
+	}
+
+	p.Salt, err = base64.RawStdEncoding.DecodeString(salt)
+	if err != nil || len(p.Salt) < 8 {
+		return nil, fmt.Errorf("invalid passphrase salt in %q", encoded)
+	}
+	return &p, nil
+}
+
+// DeriveWrappingKey stretches a passphrase into a 256-bit key-wrapping key.
+func (p *KDFParams) DeriveWrappingKey(passphrase []byte) []byte {
+	return argon2.IDKey(passphrase, p.Salt, p.Time, p.Memory, p.Threads, 32)
+}
+
+// WrapKey encrypts the drop key under a wrapping key. The drop ID is bound in
+// as associated data so a wrapped key cannot be replayed against another drop.
+func WrapKey(wrappingKey, dropKey []byte, dropID string) ([]byte, error) {
+	return sealKey(wrappingKey, dropKey, wrapAD(dropID))
+}
+
+// UnwrapKey reverses WrapKey, returning ErrWrongPassphrase if it does not open.
+func UnwrapKey(wrappingKey, wrapped []byte, dropID string) ([]byte, error) {
+	dropKey, err := openKey(wrappingKey, wrapped, wrapAD(dropID))
+	if errors.Is(err, errKeyNotOpened) {
+		return nil, ErrWrongPassphrase
+	}
+	return dropKey, err
+}
+
+// errKeyNotOpened is returned by openKey when authentication fails
+var errKeyNotOpened = errors.New("wrapped key does not open")
+
+// sealKey encrypts a drop key under a 256-bit wrapping key with AES-GCM,
+// prefixing the random nonce.
+func sealKey(wrappingKey, dropKey, ad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(wrappingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, dropKey, ad), nil
+}
+
+// openKey reverses sealKey.
+func openKey(wrappingKey, wrapped, ad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(wrappingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(wrapped) < nonceSize {
+		return nil, fmt.Errorf("wrapped key too short")
+	}
+
+	dropKey, err := gcm.Open(nil, wrapped[:nonceSize], wrapped[nonceSize:], ad)
+	if err != nil {
+		return nil, errKeyNotOpened
+	}
+	return dropKey, nil
+}
+
+func wrapAD(dropID string) []byte {
+	return []byte("codedrop-passphrase-wrap-v1|" + dropID)
+}
@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// Cheap parameters so the tests do not spend seconds in Argon2
+func testKDFParams(t *testing.T) *KDFParams {
+	p, err := NewKDFParams()
+	if err != nil {
+		t.Fatalf("Failed to create KDF params: %v", err)
+	}
+	p.Time, p.Memory, p.Threads = 1, 64, 1
+	return p
+}
+
+func TestKDFParamsEncoding(t *testing.T) {
+	p, _ := NewKDFParams()
+	encoded := p.String()
+
+	parsed, err := ParseKDFParams(encoded)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", encoded, err)
+	}
+	if parsed.Time != p.Time || parsed.Memory != p.Memory || parsed.Threads != p.Threads || !bytes.Equal(parsed.Salt, p.Salt) {
+		t.Errorf("Parsed params %+v do not match %+v", parsed, p)
+	}
+
+	for _, bad := range []string{
+		"v1-aes-gcm",
+		"argon2id$v=19$m=99999999,t=3,p=4$c2FsdHNhbHRzYWx0",
+		"argon2id$v=19$m=65536,t=3,p=4$!!",
+		"argon2id$v=16$m=65536,t=3,p=4$c2FsdHNhbHRzYWx0",
+	} {
+		if _, err := ParseKDFParams(bad); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestPassphraseWrapRoundTrip(t *testing.T) {
+	p := testKDFParams(t)
+	dropKey, _, _ := GenerateKey()
+
+	wrapped, err := WrapKey(p.DeriveWrappingKey([]byte("correct horse")), dropKey, "drop-1")
+	if err != nil {
+		t.Fatalf("Failed to wrap key: %v", err)
+	}
+
+	unwrapped, err := UnwrapKey(p.DeriveWrappingKey([]byte("correct horse")), wrapped, "drop-1")
+	if err != nil {
+		t.Fatalf("Failed to unwrap key: %v", err)
+	}
+	if !bytes.Equal(unwrapped, dropKey) {
+		t.Errorf("Unwrapped key does not match the drop key")
+	}
+
+	_, err = UnwrapKey(p.DeriveWrappingKey([]byte("battery staple")), wrapped, "drop-1")
+	if !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase for a wrong passphrase, got %v", err)
+	}
+
+	// A wrapped key copied onto another drop must not open
+	_, err = UnwrapKey(p.DeriveWrappingKey([]byte("correct horse")), wrapped, "drop-2")
+	if err == nil {
+		t.Errorf("Expected wrapped key to be bound to its drop")
+	}
+}
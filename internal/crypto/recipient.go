@@ -0,0 +1,389 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"filippo.io/edwards25519"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/ssh"
+)
+
+// Recipient key wrapping. Instead of putting the drop key in the URL, push can
+// wrap it to one or more public keys. Each wrapped copy is a Stanza; the
+// stanzas are stored with the drop and pull tries them with local identities.
+//
+// Keys use age's encodings, so identities made with `age-keygen` (and SSH
+// ed25519 keys) work as-is. The stanza format itself is CodeDrop's own.
+
+// Stanza types. The version suffix lets pull tell wrapping formats apart.
+const (
+	StanzaX25519     = "x25519-v1"
+	StanzaSSHEd25519 = "ssh-ed25519-v1"
+)
+
+// ErrIncorrectIdentity means a stanza was not wrapped to the given identity.
+var ErrIncorrectIdentity = errors.New("no identity matched any of the drop's recipients")
+
+// Stanza is the drop key wrapped to a single recipient.
+type Stanza struct {
+	Type string   `json:"type"`
+	Args [][]byte `json:"args,omitempty"`
+	Body []byte   `json:"body"`
+}
+
+// Recipient is a public key the drop key can be wrapped to.
+type Recipient interface {
+	Wrap(dropKey []byte) (*Stanza, error)
+	String() string
+}
+
+// Identity is a private key that can unwrap stanzas made for its Recipient.
+type Identity interface {
+	// Unwrap returns ErrIncorrectIdentity if the stanza is not for this identity.
+	Unwrap(s *Stanza) ([]byte, error)
+	Recipient() Recipient
+}
+
+// UnwrapStanzas tries every identity against every stanza and returns the
+// first drop key that opens.
+func UnwrapStanzas(identities []Identity, stanzas []*Stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		for _, id := range identities {
+			key, err := id.Unwrap(s)
+			if errors.Is(err, ErrIncorrectIdentity) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			return key, nil
+		}
+	}
+	return nil, ErrIncorrectIdentity
+}
+
+// MarshalStanzas encodes wrapped keys for storage with the drop.
+func MarshalStanzas(stanzas []*Stanza) ([]byte, error) {
+	return json.Marshal(stanzas)
+}
+
+// ParseStanzas decodes wrapped keys stored with a drop.
+func ParseStanzas(data []byte) ([]*Stanza, error) {
+	var stanzas []*Stanza
+	if err := json.Unmarshal(data, &stanzas); err != nil {
+		return nil, fmt.Errorf("failed to decode wrapped keys: %w", err)
+	}
+	return stanzas, nil
+}
+
+// ParseRecipient accepts an age X25519 recipient ("age1..."), a hybrid
+// post-quantum recipient ("cdpq1...") or an SSH ed25519 public key
+// ("ssh-ed25519 AAAA...").
+func ParseRecipient(s string) (Recipient, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "age1"):
+		return parseX25519Recipient(s)
+	case strings.HasPrefix(s, hybridRecipientHRP+"1"):
+		return parseHybridRecipient(s)
+	case strings.HasPrefix(s, "ssh-"):
+		return parseSSHRecipient(s)
+	default:
+		return nil, fmt.Errorf("unknown recipient type: %q", s)
+	}
+}
+
+// ParseIdentities reads an identity file: either age-style lines
+// ("AGE-SECRET-KEY-1..." or "CODEDROP-PQ-SECRET-KEY-1...", with # comments)
+// or an OpenSSH ed25519 private key.
+// passphrase is only called for encrypted SSH keys.
+func ParseIdentities(data []byte, passphrase func() ([]byte, error)) ([]Identity, error) {
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		id, err := parseSSHIdentity(data, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []Identity{id}, nil
+	}
+
+	var ids []Identity
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, err := parseIdentityLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("identity file line %d: %w", n, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no identities found")
+	}
+	return ids, nil
+}
+
+func parseIdentityLine(line string) (Identity, error) {
+	switch {
+	case strings.HasPrefix(line, "AGE-SECRET-KEY-1"):
+		return parseX25519Identity(line)
+	case strings.HasPrefix(line, hybridIdentityHRP+"1"):
+		return parseHybridIdentity(line)
+	default:
+		return nil, fmt.Errorf("unknown identity type")
+	}
+}
+
+// --- X25519 (age compatible keys) ---
+
+// X25519Recipient is an age-style X25519 public key.
+type X25519Recipient struct {
+	pub *ecdh.PublicKey
+}
+
+// X25519Identity is an age-style X25519 private key.
+type X25519Identity struct {
+	priv *ecdh.PrivateKey
+}
+
+// GenerateX25519Identity creates a fresh identity for `codedrop keygen`.
+func GenerateX25519Identity() (*X25519Identity, error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return &X25519Identity{priv: priv}, nil
+}
+
+func parseX25519Recipient(s string) (*X25519Recipient, error) {
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed recipient %q: %w", s, err)
+	}
+	if hrp != "age" {
+		return nil, fmt.Errorf("malformed recipient %q: unexpected prefix %q", s, hrp)
+	}
+	pub, err := ecdh.X25519().NewPublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("malformed recipient %q: %w", s, err)
+	}
+	return &X25519Recipient{pub: pub}, nil
+}
+
+func parseX25519Identity(s string) (*X25519Identity, error) {
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed secret key: %w", err)
+	}
+	if hrp != "age-secret-key-" {
+		return nil, fmt.Errorf("malformed secret key: unexpected prefix %q", hrp)
+	}
+	priv, err := ecdh.X25519().NewPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("malformed secret key: %w", err)
+	}
+	return &X25519Identity{priv: priv}, nil
+}
+
+func (r *X25519Recipient) Wrap(dropKey []byte) (*Stanza, error) {
+	return x25519Wrap(StanzaX25519, nil, r.pub, dropKey)
+}
+
+func (r *X25519Recipient) String() string {
+	s, _ := bech32Encode("age", r.pub.Bytes())
+	return s
+}
+
+func (i *X25519Identity) Unwrap(s *Stanza) ([]byte, error) {
+	if s.Type != StanzaX25519 || len(s.Args) != 1 {
+		return nil, ErrIncorrectIdentity
+	}
+	return x25519Unwrap(StanzaX25519, i.priv, s.Args[0], s.Body)
+}
+
+func (i *X25519Identity) Recipient() Recipient {
+	return &X25519Recipient{pub: i.priv.PublicKey()}
+}
+
+func (i *X25519Identity) String() string {
+	s, _ := bech32Encode("AGE-SECRET-KEY-", i.priv.Bytes())
+	return strings.ToUpper(s)
+}
+
+// --- SSH ed25519 keys, converted to their X25519 equivalents ---
+
+// SSHEd25519Recipient wraps to the X25519 form of an SSH ed25519 public key.
+type SSHEd25519Recipient struct {
+	sshKey ssh.PublicKey
+	pub    *ecdh.PublicKey
+}
+
+// SSHEd25519Identity unwraps with the X25519 form of an SSH ed25519 private key.
+type SSHEd25519Identity struct {
+	sshKey ssh.PublicKey
+	priv   *ecdh.PrivateKey
+}
+
+func parseSSHRecipient(s string) (*SSHEd25519Recipient, error) {
+	sshKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("malformed SSH recipient: %w", err)
+	}
+	if sshKey.Type() != ssh.KeyAlgoED25519 {
+		return nil, fmt.Errorf("unsupported SSH key type %s (only ssh-ed25519)", sshKey.Type())
+	}
+
+	edPub := sshKey.(ssh.CryptoPublicKey).CryptoPublicKey().(ed25519.PublicKey)
+	point, err := new(edwards25519.Point).SetBytes(edPub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ed25519 public key: %w", err)
+	}
+	pub, err := ecdh.X25519().NewPublicKey(point.BytesMontgomery())
+	if err != nil {
+		return nil, fmt.Errorf("invalid ed25519 public key: %w", err)
+	}
+	return &SSHEd25519Recipient{sshKey: sshKey, pub: pub}, nil
+}
+
+func parseSSHIdentity(pemBytes []byte, passphrase func() ([]byte, error)) (*SSHEd25519Identity, error) {
+	raw, err := ssh.ParseRawPrivateKey(pemBytes)
+	if _, missing := err.(*ssh.PassphraseMissingError); missing && passphrase != nil {
+		var pass []byte
+		if pass, err = passphrase(); err != nil {
+			return nil, err
+		}
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase(pemBytes, pass)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH private key: %w", err)
+	}
+
+	var edPriv ed25519.PrivateKey
+	switch k := raw.(type) {
+	case ed25519.PrivateKey:
+		edPriv = k
+	case *ed25519.PrivateKey:
+		edPriv = *k
+	default:
+		return nil, fmt.Errorf("unsupported SSH key type %T (only ed25519)", raw)
+	}
+
+	// The X25519 scalar is the clamped first half of SHA-512(seed), as in RFC 8032
+	digest := sha512.Sum512(edPriv.Seed())
+	priv, err := ecdh.X25519().NewPrivateKey(digest[:32])
+	if err != nil {
+		return nil, err
+	}
+	sshKey, err := ssh.NewPublicKey(edPriv.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &SSHEd25519Identity{sshKey: sshKey, priv: priv}, nil
+}
+
+// sshTag lets an identity skip stanzas for other SSH keys without trying them.
+func sshTag(key ssh.PublicKey) []byte {
+	h := sha256.Sum256(key.Marshal())
+	return h[:4]
+}
+
+func (r *SSHEd25519Recipient) Wrap(dropKey []byte) (*Stanza, error) {
+	return x25519Wrap(StanzaSSHEd25519, [][]byte{sshTag(r.sshKey)}, r.pub, dropKey)
+}
+
+func (r *SSHEd25519Recipient) String() string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(r.sshKey)))
+}
+
+func (i *SSHEd25519Identity) Unwrap(s *Stanza) ([]byte, error) {
+	if s.Type != StanzaSSHEd25519 || len(s.Args) != 2 || !bytes.Equal(s.Args[0], sshTag(i.sshKey)) {
+		return nil, ErrIncorrectIdentity
+	}
+	return x25519Unwrap(StanzaSSHEd25519, i.priv, s.Args[1], s.Body)
+}
+
+func (i *SSHEd25519Identity) Recipient() Recipient {
+	return &SSHEd25519Recipient{sshKey: i.sshKey, pub: i.priv.PublicKey()}
+}
+
+// --- shared X25519 wrapping ---
+
+// x25519Wrap performs ephemeral-static ECDH and seals the drop key under a key
+// derived from the shared secret. The ephemeral key is appended to args.
+func x25519Wrap(stanzaType string, args [][]byte, pub *ecdh.PublicKey, dropKey []byte) (*Stanza, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	shared, err := ephemeral.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	epk := ephemeral.PublicKey().Bytes()
+	body, err := sealWrappedKey(shared, epk, pub.Bytes(), stanzaType, dropKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Stanza{Type: stanzaType, Args: append(args, epk), Body: body}, nil
+}
+
+func x25519Unwrap(stanzaType string, priv *ecdh.PrivateKey, epk, body []byte) ([]byte, error) {
+	ephemeral, err := ecdh.X25519().NewPublicKey(epk)
+	if err != nil {
+		return nil, ErrIncorrectIdentity
+	}
+	shared, err := priv.ECDH(ephemeral) // Fails on low-order points
+	if err != nil {
+		return nil, ErrIncorrectIdentity
+	}
+	return openWrappedKey(shared, epk, priv.PublicKey().Bytes(), stanzaType, body)
+}
+
+// sealWrappedKey derives a one-time key from a shared secret and uses it to
+// seal the drop key. The key is single-use, so a zero nonce is safe.
+func sealWrappedKey(shared, epk, recipient []byte, label string, dropKey []byte) ([]byte, error) {
+	aead, err := wrapAEAD(shared, epk, recipient, label)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), dropKey, nil), nil
+}
+
+func openWrappedKey(shared, epk, recipient []byte, label string, body []byte) ([]byte, error) {
+	aead, err := wrapAEAD(shared, epk, recipient, label)
+	if err != nil {
+		return nil, err
+	}
+	dropKey, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), body, nil)
+	if err != nil {
+		return nil, ErrIncorrectIdentity
+	}
+	return dropKey, nil
+}
+
+func wrapAEAD(shared, epk, recipient []byte, label string) (cipher.AEAD, error) {
+	salt := append(append([]byte{}, epk...), recipient...)
+	wrapKey, err := hkdf.Key(sha256.New, shared, salt, "codedrop-wrap/"+label, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(wrapKey)
+}
@@ -0,0 +1,150 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestBech32RoundTrip(t *testing.T) {
+	data := []byte("codedrop recipient key material!")
+	encoded, err := bech32Encode("age", data)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	hrp, decoded, err := bech32Decode(encoded)
+	if err != nil || hrp != "age" || !bytes.Equal(decoded, data) {
+		t.Fatalf("Round trip failed: %q %x %v", hrp, decoded, err)
+	}
+
+	// A single typo must be caught by the checksum
+	typo := []byte(encoded)
+	typo[10] = map[bool]byte{true: 'q', false: 'p'}[typo[10] != 'q']
+	if _, _, err := bech32Decode(string(typo)); err == nil {
+		t.Errorf("Expected checksum failure on %q", typo)
+	}
+}
+
+func TestX25519RecipientRoundTrip(t *testing.T) {
+	id, err := GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate identity: %v", err)
+	}
+
+	// Keys survive their age-style text encodings
+	recipient, err := ParseRecipient(id.Recipient().String())
+	if err != nil {
+		t.Fatalf("Failed to parse recipient %q: %v", id.Recipient(), err)
+	}
+	ids, err := ParseIdentities([]byte("# created by a test\n"+id.String()+"\n"), nil)
+	if err != nil {
+		t.Fatalf("Failed to parse identity file: %v", err)
+	}
+	if !strings.HasPrefix(recipient.String(), "age1") || !strings.HasPrefix(id.String(), "AGE-SECRET-KEY-1") {
+		t.Errorf("Unexpected key encodings: %s / %s", recipient, id)
+	}
+
+	dropKey, _, _ := GenerateKey()
+	stanza, err := recipient.Wrap(dropKey)
+	if err != nil {
+		t.Fatalf("Failed to wrap: %v", err)
+	}
+
+	unwrapped, err := UnwrapStanzas(ids, []*Stanza{stanza})
+	if err != nil || !bytes.Equal(unwrapped, dropKey) {
+		t.Fatalf("Failed to unwrap: %v", err)
+	}
+
+	// Someone else's identity gets nothing
+	other, _ := GenerateX25519Identity()
+	if _, err := UnwrapStanzas([]Identity{other}, []*Stanza{stanza}); !errors.Is(err, ErrIncorrectIdentity) {
+		t.Errorf("Expected ErrIncorrectIdentity, got %v", err)
+	}
+
+	// Tampering with the wrapped key is detected as a non-match, not a wrong key
+	stanza.Body[0] ^= 1
+	if _, err := UnwrapStanzas(ids, []*Stanza{stanza}); !errors.Is(err, ErrIncorrectIdentity) {
+		t.Errorf("Expected tampered stanza to be rejected, got %v", err)
+	}
+}
+
+func TestSSHEd25519RecipientRoundTrip(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	sshPub, _ := ssh.NewPublicKey(pub)
+	block, err := ssh.MarshalPrivateKey(priv, "test key")
+	if err != nil {
+		t.Fatalf("Failed to marshal SSH key: %v", err)
+	}
+
+	recipient, err := ParseRecipient(string(ssh.MarshalAuthorizedKey(sshPub)))
+	if err != nil {
+		t.Fatalf("Failed to parse SSH recipient: %v", err)
+	}
+	ids, err := ParseIdentities(pem.EncodeToMemory(block), nil)
+	if err != nil {
+		t.Fatalf("Failed to parse SSH identity: %v", err)
+	}
+
+	dropKey, _, _ := GenerateKey()
+	stanza, err := recipient.Wrap(dropKey)
+	if err != nil {
+		t.Fatalf("Failed to wrap: %v", err)
+	}
+
+	// An age identity alongside must not get in the way
+	ageID, _ := GenerateX25519Identity()
+	unwrapped, err := UnwrapStanzas(append([]Identity{ageID}, ids...), []*Stanza{stanza})
+	if err != nil || !bytes.Equal(unwrapped, dropKey) {
+		t.Fatalf("Failed to unwrap with SSH identity: %v", err)
+	}
+}
+
+func TestStanzasRoundTrip(t *testing.T) {
+	id, _ := GenerateX25519Identity()
+	dropKey, _, _ := GenerateKey()
+	stanza, _ := id.Recipient().Wrap(dropKey)
+
+	data, err := MarshalStanzas([]*Stanza{stanza})
+	if err != nil {
+		t.Fatalf("Failed to marshal stanzas: %v", err)
+	}
+	stanzas, err := ParseStanzas(data)
+	if err != nil || len(stanzas) != 1 {
+		t.Fatalf("Failed to parse stanzas: %v", err)
+	}
+	if unwrapped, err := id.Unwrap(stanzas[0]); err != nil || !bytes.Equal(unwrapped, dropKey) {
+		t.Errorf("Stanza did not survive encoding: %v", err)
+	}
+}
+
+func TestParseRecipientRejectsGarbage(t *testing.T) {
+	for _, bad := range []string{"", "bob", "age1notbech32", "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQ"} {
+		if _, err := ParseRecipient(bad); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestAgeKeygenCompatibility(t *testing.T) {
+	// A key pair produced by age-keygen
+	const ageIdentity = "AGE-SECRET-KEY-12MKV44ZDQWSKDU698EWSJRTRL5F0CQ5RK7CPT8K2U9PS95LTYG0QHF98ZJ"
+	const ageRecipient = "age1lrxa65tqsals2uxrxr32ky8yqg674f4nn94q29rt4d57rls4fegq5up9qa"
+
+	ids, err := ParseIdentities([]byte(ageIdentity), nil)
+	if err != nil {
+		t.Fatalf("Failed to parse age identity: %v", err)
+	}
+	if got := ids[0].Recipient().String(); got != ageRecipient {
+		t.Errorf("Derived recipient %s, want %s", got, ageRecipient)
+	}
+	if got := ids[0].(*X25519Identity).String(); got != ageIdentity {
+		t.Errorf("Re-encoded identity %s, want %s", got, ageIdentity)
+	}
+}
@@ -0,0 +1,48 @@
+package crypto
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Relay frames. `codedrop send` streams chunks through the server without a
+// manifest, since nothing is stored. Instead each frame is bound to its
+// position and to whether it is the last one, so the relay cannot reorder,
+// drop or truncate the stream without the receiver noticing.
+//
+// Layout: flag (1 byte, 1 on the final frame) || sealed chunk.
+
+const (
+	relayFrameMore  = 0
+	relayFrameFinal = 1
+)
+
+// SealRelayChunk encrypts the chunk at index for a relay transfer.
+func SealRelayChunk(suite *Suite, key []byte, index uint64, final bool, plaintext []byte) ([]byte, error) {
+	flag := byte(relayFrameMore)
+	if final {
+		flag = relayFrameFinal
+	}
+	sealed, err := suite.seal(key, plaintext, relayAD(index, flag))
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{flag}, sealed...), nil
+}
+
+// OpenRelayChunk decrypts the frame expected at index and reports whether it
+// was the final one.
+func OpenRelayChunk(suite *Suite, key []byte, index uint64, frame []byte) ([]byte, bool, error) {
+	if len(frame) < 1 || frame[0] > relayFrameFinal {
+		return nil, false, fmt.Errorf("invalid relay frame")
+	}
+	plaintext, err := suite.open(key, frame[1:], relayAD(index, frame[0]))
+	if err != nil {
+		return nil, false, err
+	}
+	return plaintext, frame[0] == relayFrameFinal, nil
+}
+
+func relayAD(index uint64, flag byte) []byte {
+	return []byte("codedrop-relay-v1|" + strconv.FormatUint(index, 10) + "|" + strconv.Itoa(int(flag)))
+}
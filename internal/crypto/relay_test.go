@@ -0,0 +1,44 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRelayChunkRoundTrip(t *testing.T) {
+	key, _, _ := GenerateKey()
+	suite, _ := SelectSuite(CipherAESGCM, KeyPrivate)
+
+	frame, err := SealRelayChunk(suite, key, 3, true, []byte("last chunk"))
+	if err != nil {
+		t.Fatalf("Failed to seal frame: %v", err)
+	}
+	plaintext, final, err := OpenRelayChunk(suite, key, 3, frame)
+	if err != nil || !final || !bytes.Equal(plaintext, []byte("last chunk")) {
+		t.Errorf("OpenRelayChunk = %q, %v, %v", plaintext, final, err)
+	}
+}
+
+func TestRelayChunkIsBoundToPosition(t *testing.T) {
+	key, _, _ := GenerateKey()
+	suite, _ := SelectSuite(CipherXChaCha, KeyPrivate)
+
+	frame, _ := SealRelayChunk(suite, key, 0, false, []byte("first chunk"))
+	if _, _, err := OpenRelayChunk(suite, key, 1, frame); err == nil {
+		t.Errorf("Expected a reordered frame to be rejected")
+	}
+
+	// Marking a middle frame as final would truncate the stream
+	truncated := append([]byte{1}, frame[1:]...)
+	if _, _, err := OpenRelayChunk(suite, key, 0, truncated); err == nil {
+		t.Errorf("Expected a forged final flag to be rejected")
+	}
+
+	otherKey, _, _ := GenerateKey()
+	if _, _, err := OpenRelayChunk(suite, otherKey, 0, frame); err == nil {
+		t.Errorf("Expected the wrong key to be rejected")
+	}
+	if _, _, err := OpenRelayChunk(suite, key, 0, nil); err == nil {
+		t.Errorf("Expected an empty frame to be rejected")
+	}
+}
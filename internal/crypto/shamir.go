@@ -0,0 +1,133 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// Shamir secret sharing over GF(2^8), used by push --split. Every byte of the
+// key is the constant term of its own random polynomial of degree
+// threshold-1; share x holds the polynomials evaluated at x. Any threshold
+// shares reconstruct the key, fewer reveal nothing about it.
+//
+// A share is encoded as [threshold, x, y...], so pull knows how many shares
+// it still needs.
+
+// MaxShares is the most shares a key can be split into (x runs from 1 to 255).
+const MaxShares = 255
+
+// SplitKey splits a key into n shares, any threshold of which recover it.
+func SplitKey(key []byte, threshold, n int) ([][]byte, error) {
+	if threshold < 2 || threshold > n || n > MaxShares {
+		return nil, fmt.Errorf("invalid split %d-of-%d: need 2 <= threshold <= shares <= %d", threshold, n, MaxShares)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("cannot split an empty key")
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, 2+len(key))
+		shares[i][0] = byte(threshold)
+		shares[i][1] = byte(i + 1)
+	}
+
+	coeffs := make([]byte, threshold)
+	for b, secret := range key {
+		coeffs[0] = secret
+		if _, err := rand.Read(coeffs[1:]); err != nil {
+			return nil, fmt.Errorf("failed to generate share: %w", err)
+		}
+		for _, share := range shares {
+			share[2+b] = evalPolynomial(coeffs, share[1])
+		}
+	}
+	return shares, nil
+}
+
+// CombineShares reconstructs a key from at least threshold distinct shares.
+// A wrong or corrupted share yields a wrong key, which the caller detects when
+// the manifest fails to open.
+func CombineShares(shares [][]byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no shares given")
+	}
+	threshold, err := ShareThreshold(shares[0])
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[byte]bool{}
+	var distinct [][]byte
+	for _, share := range shares {
+		if len(share) != len(shares[0]) || share[0] != shares[0][0] || share[1] == 0 {
+			return nil, fmt.Errorf("shares do not belong to the same split")
+		}
+		if !seen[share[1]] {
+			seen[share[1]] = true
+			distinct = append(distinct, share)
+		}
+	}
+	if len(distinct) < threshold {
+		return nil, fmt.Errorf("need %d distinct shares, have %d", threshold, len(distinct))
+	}
+	distinct = distinct[:threshold]
+
+	// Lagrange interpolation at x = 0
+	key := make([]byte, len(shares[0])-2)
+	for i, si := range distinct {
+		basis := byte(1)
+		for j, sj := range distinct {
+			if i != j {
+				// x_j / (x_j - x_i); subtraction is XOR in GF(2^8)
+				basis = gfMul(basis, gfMul(sj[1], gfInv(sj[1]^si[1])))
+			}
+		}
+		for b := range key {
+			key[b] ^= gfMul(si[2+b], basis)
+		}
+	}
+	return key, nil
+}
+
+// ShareThreshold returns how many shares are needed to recover the key.
+func ShareThreshold(share []byte) (int, error) {
+	if len(share) < 3 || share[0] < 2 || share[1] == 0 {
+		return 0, fmt.Errorf("malformed key share")
+	}
+	return int(share[0]), nil
+}
+
+// evalPolynomial evaluates coeffs (lowest degree first) at x with Horner's rule.
+func evalPolynomial(coeffs []byte, x byte) byte {
+	var y byte
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coeffs[i]
+	}
+	return y
+}
+
+// gfMul multiplies in GF(2^8) modulo x^8+x^4+x^3+x+1 (the AES field) without
+// data-dependent branches.
+func gfMul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= a & -(b & 1)
+		carry := a >> 7
+		a = a<<1 ^ 0x1b&-carry
+		b >>= 1
+	}
+	return p
+}
+
+// gfInv returns a^254 = a^-1 (and 0 for 0).
+func gfInv(a byte) byte {
+	result := byte(1)
+	for e := 254; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			result = gfMul(result, a)
+		}
+		a = gfMul(a, a)
+	}
+	return result
+}
@@ -0,0 +1,76 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitAndCombineKey(t *testing.T) {
+	key, _, _ := GenerateKey()
+	shares, err := SplitKey(key, 2, 3)
+	if err != nil {
+		t.Fatalf("Failed to split key: %v", err)
+	}
+	if len(shares) != 3 {
+		t.Fatalf("Expected 3 shares, got %d", len(shares))
+	}
+
+	// Every pair of shares, in either order, recovers the key
+	for i := range shares {
+		for j := range shares {
+			if i == j {
+				continue
+			}
+			got, err := CombineShares([][]byte{shares[i], shares[j]})
+			if err != nil || !bytes.Equal(got, key) {
+				t.Errorf("Shares %d+%d did not recover the key: %v", i, j, err)
+			}
+		}
+	}
+
+	// One share is not enough, and repeating it does not help
+	if _, err := CombineShares([][]byte{shares[0], shares[0]}); err == nil {
+		t.Errorf("Expected a single (repeated) share to be rejected")
+	}
+}
+
+func TestSplitKeyThresholdOfFive(t *testing.T) {
+	key, _, _ := GenerateKey()
+	shares, err := SplitKey(key, 3, 5)
+	if err != nil {
+		t.Fatalf("Failed to split key: %v", err)
+	}
+
+	got, err := CombineShares([][]byte{shares[4], shares[1], shares[2]})
+	if err != nil || !bytes.Equal(got, key) {
+		t.Fatalf("3 of 5 shares did not recover the key: %v", err)
+	}
+
+	// Below the threshold the shares say nothing useful
+	if _, err := CombineShares(shares[:2]); err == nil {
+		t.Errorf("Expected 2 of 5 shares to be rejected for a 3-of-5 split")
+	}
+
+	// A share from another split is refused or produces the wrong key
+	other, _ := SplitKey(key, 2, 3)
+	if got, err := CombineShares([][]byte{shares[0], shares[1], other[2]}); err == nil && bytes.Equal(got, key) {
+		t.Errorf("Mixing splits should not recover the key")
+	}
+}
+
+func TestSplitKeyRejectsBadParameters(t *testing.T) {
+	key, _, _ := GenerateKey()
+	for _, p := range [][2]int{{1, 3}, {4, 3}, {2, 256}, {0, 0}} {
+		if _, err := SplitKey(key, p[0], p[1]); err == nil {
+			t.Errorf("Expected %d-of-%d to be rejected", p[0], p[1])
+		}
+	}
+}
+
+func TestGaloisFieldInverse(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		if got := gfMul(byte(a), gfInv(byte(a))); got != 1 {
+			t.Fatalf("%d * inv(%d) = %d", a, a, got)
+		}
+	}
+}
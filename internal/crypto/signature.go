@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Sender signatures. The sender signs the manifest (which pins every chunk and
+// the file hash) together with the sealed file metadata, using an Ed25519 key
+// in SSH form so ssh-agent can hold it. The signature travels inside the
+// encrypted manifest, so the server never learns who sent a drop.
+
+// ErrUnsigned means the drop carries no sender signature.
+var ErrUnsigned = errors.New("drop is not signed")
+
+// signatureContext domain-separates manifest signatures from anything else
+// the same SSH key might sign.
+const signatureContext = "codedrop-manifest-signature-v1\n"
+
+// ManifestSignature identifies the sender and proves they produced the drop.
+type ManifestSignature struct {
+	PublicKey string `json:"public_key"` // authorized_keys format, e.g. "ssh-ed25519 AAAA..."
+	Format    string `json:"format"`
+	Blob      []byte `json:"blob"`
+}
+
+// SignManifest signs the manifest and the sealed metadata with an Ed25519 SSH
+// signer (a key file or an ssh-agent key) and stores the signature in m.
+func SignManifest(m *Manifest, sealedMetadata []byte, signer ssh.Signer) error {
+	if signer.PublicKey().Type() != ssh.KeyAlgoED25519 {
+		return fmt.Errorf("unsupported signing key type %s (only ssh-ed25519)", signer.PublicKey().Type())
+	}
+	m.Signature = nil
+	payload, err := signedPayload(m, sealedMetadata)
+	if err != nil {
+		return err
+	}
+
+	sig, err := signer.Sign(nil, payload)
+	if err != nil {
+		return fmt.Errorf("failed to sign manifest: %w", err)
+	}
+	m.Signature = &ManifestSignature{
+		PublicKey: strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))),
+		Format:    sig.Format,
+		Blob:      sig.Blob,
+	}
+	return nil
+}
+
+// VerifySignature checks the sender signature and returns the signer's key.
+// It returns ErrUnsigned for unsigned drops; any other error means the drop
+// claims a signer but was not produced by them.
+func (m *Manifest) VerifySignature(sealedMetadata []byte) (ssh.PublicKey, error) {
+	if m.Signature == nil {
+		return nil, ErrUnsigned
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(m.Signature.PublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("malformed signer key: %w", err)
+	}
+	if pub.Type() != ssh.KeyAlgoED25519 {
+		return nil, fmt.Errorf("unsupported signer key type %s", pub.Type())
+	}
+
+	unsigned := *m
+	unsigned.Signature = nil
+	payload, err := signedPayload(&unsigned, sealedMetadata)
+	if err != nil {
+		return nil, err
+	}
+	sig := &ssh.Signature{Format: m.Signature.Format, Blob: m.Signature.Blob}
+	if err := pub.Verify(payload, sig); err != nil {
+		return nil, fmt.Errorf("invalid sender signature: %w", err)
+	}
+	return pub, nil
+}
+
+// signedPayload is the context string, the hash of the sealed metadata (so
+// the file name can't be swapped), and the manifest without its signature.
+func signedPayload(m *Manifest, sealedMetadata []byte) ([]byte, error) {
+	encoded, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	metaHash := sha256.Sum256(sealedMetadata)
+
+	payload := append([]byte(signatureContext), metaHash[:]...)
+	return append(payload, encoded...), nil
+}
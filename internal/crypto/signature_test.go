@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	return signer
+}
+
+func TestManifestSignatureRoundTrip(t *testing.T) {
+	key, _, _ := GenerateKey()
+	signer := newTestSigner(t)
+	sealedMeta, _ := SealMetadata(testSuite, key, &FileMetadata{Name: "release.tar.gz", Size: 3})
+
+	m := testManifest(t, key, []byte("abc"))
+	if err := SignManifest(m, sealedMeta, signer); err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+
+	// The signature travels inside the encrypted manifest
+	sealed, _ := SealManifest(testSuite, key, m)
+	opened, err := OpenManifest(testSuite, key, sealed)
+	if err != nil {
+		t.Fatalf("Failed to open manifest: %v", err)
+	}
+	pub, err := opened.VerifySignature(sealedMeta)
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if !bytes.Equal(pub.Marshal(), signer.PublicKey().Marshal()) {
+		t.Errorf("Verified signer does not match the signing key")
+	}
+}
+
+func TestManifestSignatureRejectsTampering(t *testing.T) {
+	key, _, _ := GenerateKey()
+	signer := newTestSigner(t)
+	sealedMeta, _ := SealMetadata(testSuite, key, &FileMetadata{Name: "release.tar.gz", Size: 3})
+
+	m := testManifest(t, key, []byte("abc"))
+	if err := SignManifest(m, sealedMeta, signer); err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+
+	// Someone holding the drop key re-seals the metadata under a new name
+	otherMeta, _ := SealMetadata(testSuite, key, &FileMetadata{Name: "installer.sh", Size: 3})
+	if _, err := m.VerifySignature(otherMeta); err == nil {
+		t.Errorf("Expected swapped metadata to fail verification")
+	}
+
+	// ...or points the signed manifest at different content
+	m.FileHash = "00"
+	if _, err := m.VerifySignature(sealedMeta); err == nil {
+		t.Errorf("Expected modified manifest to fail verification")
+	}
+
+	// ...or claims a different signer
+	m = testManifest(t, key, []byte("abc"))
+	SignManifest(m, sealedMeta, signer)
+	impostor := newTestSigner(t)
+	m.Signature.PublicKey = string(ssh.MarshalAuthorizedKey(impostor.PublicKey()))
+	if _, err := m.VerifySignature(sealedMeta); err == nil {
+		t.Errorf("Expected substituted signer key to fail verification")
+	}
+}
+
+func TestManifestSignatureUnsignedAndUnsupported(t *testing.T) {
+	key, _, _ := GenerateKey()
+	m := testManifest(t, key, []byte("abc"))
+	if _, err := m.VerifySignature(nil); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("Expected ErrUnsigned, got %v", err)
+	}
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecSigner, _ := ssh.NewSignerFromKey(ecKey)
+	if err := SignManifest(m, nil, ecSigner); err == nil {
+		t.Errorf("Expected non-ed25519 signer to be rejected")
+	}
+}
@@ -0,0 +1,211 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/edwards25519"
+	"golang.org/x/crypto/argon2"
+)
+
+// Short codes. A code like 7-crossbow-lantern-amber-anvil-bread has far too
+// little entropy to encrypt anything directly, so it is never used that way. The words are
+// stretched with Argon2id into a SPAKE2 password and a sealing key:
+//
+//   - The sender seals the URL fragment under the sealing key and registers
+//     the password (the verifier) with the server.
+//   - To redeem the code, pull runs SPAKE2 against the server. Every guess
+//     costs one online exchange, which the server counts and rate-limits.
+//     Nothing sent over the wire lets an eavesdropper test guesses offline.
+//
+// The server holds the verifier and the sealed fragment, so a compromised
+// server can test guesses offline, without the claim limit. The code must
+// therefore carry enough words that an offline search is hopeless with every
+// guess costing an Argon2id evaluation; the CLI uses five (45 bits).
+
+// ErrWrongCode is returned when a short code does not match.
+var ErrWrongCode = errors.New("incorrect short code")
+
+var (
+	shortCodeSealAD  = []byte("codedrop-short-code-v1")
+	shortCodeClaimAD = []byte("codedrop-short-code-claim-v1")
+	spake2Context    = []byte("codedrop-spake2-edwards25519-v1")
+)
+
+// spake2M and spake2N are the SPAKE2 blinding points. They are derived by
+// hashing fixed strings to the curve, so nobody knows their discrete logs.
+var (
+	spake2M = hashToPoint("codedrop-spake2-M")
+	spake2N = hashToPoint("codedrop-spake2-N")
+)
+
+func hashToPoint(label string) *edwards25519.Point {
+	for i := byte(0); ; i++ {
+		h := sha256.Sum256(append([]byte(label), i))
+		p, err := new(edwards25519.Point).SetBytes(h[:])
+		if err != nil {
+			continue // Not a valid encoding; try the next counter
+		}
+		p.MultByCofactor(p)
+		if p.Equal(edwards25519.NewIdentityPoint()) == 0 {
+			return p
+		}
+	}
+}
+
+// ShortCodeSecret is what the words of a short code stretch into.
+type ShortCodeSecret struct {
+	Verifier []byte // SPAKE2 password scalar; registered with the server
+	SealKey  []byte // Seals the URL fragment; never leaves the client
+}
+
+// DeriveShortCodeSecret stretches the secret words of a short code.
+func DeriveShortCodeSecret(words string, p *KDFParams) (*ShortCodeSecret, error) {
+	stretched := argon2.IDKey([]byte(words), p.Salt, p.Time, p.Memory, p.Threads, 96)
+	w, err := edwards25519.NewScalar().SetUniformBytes(stretched[:64])
+	if err != nil {
+		return nil, err
+	}
+	return &ShortCodeSecret{Verifier: w.Bytes(), SealKey: stretched[64:]}, nil
+}
+
+// SealShortCodeFragment encrypts a URL fragment for a short code. It is bound
+// to the drop so the server cannot point the code at another drop.
+func SealShortCodeFragment(sealKey []byte, fragment, dropID string) ([]byte, error) {
+	return sealWithAD(sealKey, []byte(fragment), shortCodeAD(shortCodeSealAD, dropID))
+}
+
+// OpenShortCodeFragment reverses SealShortCodeFragment.
+func OpenShortCodeFragment(sealKey, sealed []byte, dropID string) (string, error) {
+	fragment, err := openWithAD(sealKey, sealed, shortCodeAD(shortCodeSealAD, dropID))
+	if err != nil {
+		return "", ErrWrongCode
+	}
+	return string(fragment), nil
+}
+
+// SealShortCodeClaim encrypts the server's answer under the SPAKE2 session key.
+func SealShortCodeClaim(sessionKey, payload []byte) ([]byte, error) {
+	return sealWithAD(sessionKey, payload, shortCodeClaimAD)
+}
+
+// OpenShortCodeClaim reverses SealShortCodeClaim. A wrong code yields a
+// different session key, so it fails here.
+func OpenShortCodeClaim(sessionKey, sealed []byte) ([]byte, error) {
+	payload, err := openWithAD(sessionKey, sealed, shortCodeClaimAD)
+	if err != nil {
+		return nil, ErrWrongCode
+	}
+	return payload, nil
+}
+
+func shortCodeAD(purpose []byte, dropID string) []byte {
+	return append(append([]byte{}, purpose...), "|"+dropID...)
+}
+
+// PAKEClient is the redeeming side of a SPAKE2 exchange.
+type PAKEClient struct {
+	w, x    *edwards25519.Scalar
+	message []byte
+	context []byte
+}
+
+// NewPAKEClient starts an exchange. Message is sent to the server; context
+// (the code's nameplate) is bound into the session key.
+func NewPAKEClient(verifier, context []byte) (*PAKEClient, error) {
+	w, err := edwards25519.NewScalar().SetCanonicalBytes(verifier)
+	if err != nil {
+		return nil, fmt.Errorf("invalid verifier: %w", err)
+	}
+	x, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+
+	// T = x*G + w*M
+	t := new(edwards25519.Point).ScalarBaseMult(x)
+	t.Add(t, new(edwards25519.Point).ScalarMult(w, spake2M))
+	return &PAKEClient{w: w, x: x, message: t.Bytes(), context: context}, nil
+}
+
+// Message returns the client's SPAKE2 message.
+func (c *PAKEClient) Message() []byte {
+	return c.message
+}
+
+// Finish processes the server's message and returns the session key. A wrong
+// code does not fail here; it yields a key that opens nothing.
+func (c *PAKEClient) Finish(serverMessage []byte) ([]byte, error) {
+	s, err := new(edwards25519.Point).SetBytes(serverMessage)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server message: %w", err)
+	}
+
+	// Z = h * x * (S - w*N)
+	z := new(edwards25519.Point).ScalarMult(c.w, spake2N)
+	z.Subtract(s, z)
+	z.ScalarMult(c.x, z)
+	return spake2SessionKey(c.context, c.message, serverMessage, z, c.w)
+}
+
+// PAKEServerRespond answers a client message for the given verifier and
+// returns the server's message and the session key.
+func PAKEServerRespond(verifier, context, clientMessage []byte) (message, sessionKey []byte, err error) {
+	w, err := edwards25519.NewScalar().SetCanonicalBytes(verifier)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid verifier: %w", err)
+	}
+	t, err := new(edwards25519.Point).SetBytes(clientMessage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid client message: %w", err)
+	}
+	y, err := randomScalar()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// S = y*G + w*N
+	s := new(edwards25519.Point).ScalarBaseMult(y)
+	s.Add(s, new(edwards25519.Point).ScalarMult(w, spake2N))
+	message = s.Bytes()
+
+	// Z = h * y * (T - w*M)
+	z := new(edwards25519.Point).ScalarMult(w, spake2M)
+	z.Subtract(t, z)
+	z.ScalarMult(y, z)
+	sessionKey, err = spake2SessionKey(context, clientMessage, message, z, w)
+	if err != nil {
+		return nil, nil, err
+	}
+	return message, sessionKey, nil
+}
+
+// spake2SessionKey clears the cofactor and hashes the whole transcript, as in RFC 9382.
+func spake2SessionKey(context, t, s []byte, z *edwards25519.Point, w *edwards25519.Scalar) ([]byte, error) {
+	z.MultByCofactor(z)
+	if z.Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return nil, fmt.Errorf("invalid SPAKE2 message: low-order point")
+	}
+
+	h := sha256.New()
+	for _, part := range [][]byte{spake2Context, context, t, s, z.Bytes(), w.Bytes()} {
+		var length [8]byte
+		binary.LittleEndian.PutUint64(length[:], uint64(len(part)))
+		h.Write(length[:])
+		h.Write(part)
+	}
+	return h.Sum(nil), nil
+}
+
+func randomScalar() (*edwards25519.Scalar, error) {
+	var seed [sha512.Size]byte
+	if _, err := io.ReadFull(rand.Reader, seed[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate scalar: %w", err)
+	}
+	return edwards25519.NewScalar().SetUniformBytes(seed[:])
+}
@@ -0,0 +1,112 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+// testKDF keeps Argon2id cheap in tests.
+func testKDF() *KDFParams {
+	return &KDFParams{Time: 1, Memory: 64, Threads: 1, Salt: bytes.Repeat([]byte{7}, saltSize)}
+}
+
+func TestPAKEAgreesOnMatchingCode(t *testing.T) {
+	secret, err := DeriveShortCodeSecret("crossbow-lantern", testKDF())
+	if err != nil {
+		t.Fatalf("Failed to derive secret: %v", err)
+	}
+
+	client, err := NewPAKEClient(secret.Verifier, []byte("7"))
+	if err != nil {
+		t.Fatalf("Failed to start exchange: %v", err)
+	}
+	reply, serverKey, err := PAKEServerRespond(secret.Verifier, []byte("7"), client.Message())
+	if err != nil {
+		t.Fatalf("Server failed to respond: %v", err)
+	}
+	clientKey, err := client.Finish(reply)
+	if err != nil {
+		t.Fatalf("Client failed to finish: %v", err)
+	}
+	if !bytes.Equal(clientKey, serverKey) {
+		t.Errorf("Expected both sides to derive the same session key")
+	}
+
+	claim, _ := SealShortCodeClaim(serverKey, []byte("payload"))
+	if got, err := OpenShortCodeClaim(clientKey, claim); err != nil || string(got) != "payload" {
+		t.Errorf("Failed to open claim: %v", err)
+	}
+}
+
+func TestPAKERejectsWrongCode(t *testing.T) {
+	right, _ := DeriveShortCodeSecret("crossbow-lantern", testKDF())
+	wrong, _ := DeriveShortCodeSecret("crossbow-lanterns", testKDF())
+
+	client, _ := NewPAKEClient(wrong.Verifier, []byte("7"))
+	reply, serverKey, err := PAKEServerRespond(right.Verifier, []byte("7"), client.Message())
+	if err != nil {
+		t.Fatalf("Server failed to respond: %v", err)
+	}
+	clientKey, _ := client.Finish(reply)
+	if bytes.Equal(clientKey, serverKey) {
+		t.Fatalf("A wrong code must not yield the session key")
+	}
+
+	claim, _ := SealShortCodeClaim(serverKey, []byte("payload"))
+	if _, err := OpenShortCodeClaim(clientKey, claim); !errors.Is(err, ErrWrongCode) {
+		t.Errorf("Expected ErrWrongCode, got %v", err)
+	}
+
+	// The nameplate is bound in too
+	client, _ = NewPAKEClient(right.Verifier, []byte("8"))
+	reply, serverKey, _ = PAKEServerRespond(right.Verifier, []byte("7"), client.Message())
+	if clientKey, _ := client.Finish(reply); bytes.Equal(clientKey, serverKey) {
+		t.Errorf("Expected a different nameplate to change the session key")
+	}
+}
+
+func TestPAKERejectsInvalidPoints(t *testing.T) {
+	secret, _ := DeriveShortCodeSecret("crossbow-lantern", testKDF())
+	w, _ := edwards25519.NewScalar().SetCanonicalBytes(secret.Verifier)
+
+	// (0, -1) has order 2. A message that differs from the honest one by a
+	// low-order point would otherwise pin the shared secret to a small set.
+	orderTwo, err := new(edwards25519.Point).SetBytes(append([]byte{0xec}, append(bytes.Repeat([]byte{0xff}, 30), 0x7f)...))
+	if err != nil {
+		t.Fatalf("Failed to decode low-order point: %v", err)
+	}
+
+	client, _ := NewPAKEClient(secret.Verifier, nil)
+	forged := new(edwards25519.Point).ScalarMult(w, spake2N)
+	forged.Add(forged, orderTwo)
+	if _, err := client.Finish(forged.Bytes()); err == nil {
+		t.Errorf("Expected a low-order server message to be rejected")
+	}
+
+	forged = new(edwards25519.Point).ScalarMult(w, spake2M)
+	forged.Add(forged, orderTwo)
+	if _, _, err := PAKEServerRespond(secret.Verifier, nil, forged.Bytes()); err == nil {
+		t.Errorf("Expected a low-order client message to be rejected")
+	}
+
+	if _, _, err := PAKEServerRespond(secret.Verifier, nil, []byte("short")); err == nil {
+		t.Errorf("Expected a malformed client message to be rejected")
+	}
+}
+
+func TestShortCodeFragmentIsBoundToDrop(t *testing.T) {
+	secret, _ := DeriveShortCodeSecret("crossbow-lantern", testKDF())
+	sealed, err := SealShortCodeFragment(secret.SealKey, "k=AAAA", "drop-1")
+	if err != nil {
+		t.Fatalf("Failed to seal fragment: %v", err)
+	}
+	if got, err := OpenShortCodeFragment(secret.SealKey, sealed, "drop-1"); err != nil || got != "k=AAAA" {
+		t.Errorf("Failed to open fragment: %q %v", got, err)
+	}
+	if _, err := OpenShortCodeFragment(secret.SealKey, sealed, "drop-2"); err == nil {
+		t.Errorf("Expected the fragment to be rejected for another drop")
+	}
+}
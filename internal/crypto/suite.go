@@ -0,0 +1,153 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Cipher suites. A drop's algorithm identifier names one suite: the AEAD and
+// how keys and nonces are chosen. Everything under the drop key (chunks,
+// metadata, manifest) is sealed with the suite's AEAD, and pull dispatches on
+// the identifier, so adding a suite never changes how existing drops decrypt.
+
+// Ciphers.
+const (
+	CipherAESGCM  = "aes-256-gcm"
+	CipherXChaCha = "xchacha20-poly1305"
+	DefaultCipher = CipherAESGCM
+)
+
+// Key modes. They decide where the drop key and chunk nonces come from.
+const (
+	KeyConvergent = "convergent" // key = SHA-256(file), nonce = SHA-256(chunk)
+	KeyPrivate    = "private"    // random key and nonces
+	KeyTeam       = "team"       // key from the team secret, nonce = HMAC(key, chunk)
+)
+
+// XChaCha20-Poly1305 algorithm identifiers. Its 192-bit nonces leave a far
+// wider margin than GCM's 96 bits for random nonces, and it is constant-time
+// in software on machines without AES instructions.
+const (
+	AlgXChaChaConvergent = "v1-xchacha20-poly1305"
+	AlgXChaChaPrivate    = "v1-xchacha20-poly1305-private"
+	AlgXChaChaTeam       = "v1-xchacha20-poly1305-team"
+)
+
+// Suite is one registered algorithm identifier.
+type Suite struct {
+	ID      string // Stored with the drop and in its manifest
+	Version int    // Format version; 2 and up are key-committing
+	Cipher  string
+	KeyMode string
+
+	newAEAD   func(key []byte) (cipher.AEAD, error)
+	nonceSize int
+}
+
+// suites is the registry. Identifiers are never reused or removed, so old
+// drops keep decrypting.
+var suites = []*Suite{
+	{ID: AlgConvergent, Version: 1, Cipher: CipherAESGCM, KeyMode: KeyConvergent, newAEAD: newAESGCM, nonceSize: 12},
+	{ID: AlgPrivate, Version: 1, Cipher: CipherAESGCM, KeyMode: KeyPrivate, newAEAD: newAESGCM, nonceSize: 12},
+	{ID: AlgTeam, Version: 1, Cipher: CipherAESGCM, KeyMode: KeyTeam, newAEAD: newAESGCM, nonceSize: 12},
+	{ID: AlgXChaChaConvergent, Version: 1, Cipher: CipherXChaCha, KeyMode: KeyConvergent, newAEAD: chacha20poly1305.NewX, nonceSize: 24},
+	{ID: AlgXChaChaPrivate, Version: 1, Cipher: CipherXChaCha, KeyMode: KeyPrivate, newAEAD: chacha20poly1305.NewX, nonceSize: 24},
+	{ID: AlgXChaChaTeam, Version: 1, Cipher: CipherXChaCha, KeyMode: KeyTeam, newAEAD: chacha20poly1305.NewX, nonceSize: 24},
+
+	{ID: AlgV2Convergent, Version: 2, Cipher: CipherAESGCM, KeyMode: KeyConvergent, newAEAD: newAESGCM, nonceSize: 12},
+	{ID: AlgV2Private, Version: 2, Cipher: CipherAESGCM, KeyMode: KeyPrivate, newAEAD: newAESGCM, nonceSize: 12},
+	{ID: AlgV2Team, Version: 2, Cipher: CipherAESGCM, KeyMode: KeyTeam, newAEAD: newAESGCM, nonceSize: 12},
+	{ID: AlgV2XChaChaConvergent, Version: 2, Cipher: CipherXChaCha, KeyMode: KeyConvergent, newAEAD: chacha20poly1305.NewX, nonceSize: 24},
+	{ID: AlgV2XChaChaPrivate, Version: 2, Cipher: CipherXChaCha, KeyMode: KeyPrivate, newAEAD: chacha20poly1305.NewX, nonceSize: 24},
+	{ID: AlgV2XChaChaTeam, Version: 2, Cipher: CipherXChaCha, KeyMode: KeyTeam, newAEAD: chacha20poly1305.NewX, nonceSize: 24},
+}
+
+// LookupSuite returns the suite for a drop's algorithm identifier. Drops from
+// before algorithms were recorded have an empty identifier and are convergent.
+func LookupSuite(alg string) (*Suite, error) {
+	if alg == "" {
+		alg = AlgConvergent
+	}
+	for _, s := range suites {
+		if s.ID == alg {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported encryption algorithm %q", alg)
+}
+
+// SelectSuite returns the newest suite for a cipher and key mode, as chosen by push.
+func SelectSuite(cipherName, keyMode string) (*Suite, error) {
+	var best *Suite
+	for _, s := range suites {
+		if s.Cipher == cipherName && s.KeyMode == keyMode && (best == nil || s.Version > best.Version) {
+			best = s
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+	return nil, fmt.Errorf("unsupported cipher %q (supported: %s, %s)", cipherName, CipherAESGCM, CipherXChaCha)
+}
+
+// Encrypt seals one chunk. The nonce is prepended to the ciphertext, and for
+// version 2 suites the key commitment before that. For the version 1 AES-GCM
+// suites the layout is that of Encrypt, so Decrypt opens their chunks.
+func (s *Suite) Encrypt(key, plaintext []byte) ([]byte, error) {
+	sealed, err := s.encrypt(key, plaintext)
+	if err != nil || s.Version < 2 {
+		return sealed, err
+	}
+	return addCommitment(key, sealed, s.nonceSize), nil
+}
+
+func (s *Suite) encrypt(key, plaintext []byte) ([]byte, error) {
+	switch s.KeyMode {
+	case KeyTeam:
+		return sealAEAD(s.newAEAD, key, plaintext, nil)
+	case KeyConvergent, KeyPrivate:
+		aead, err := s.newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if s.KeyMode == KeyConvergent {
+			hash := sha256.Sum256(plaintext)
+			copy(nonce, hash[:])
+		} else if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return nil, fmt.Errorf("failed to generate nonce: %w", err)
+		}
+		return aead.Seal(nonce, nonce, plaintext, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown key mode %q", s.KeyMode)
+	}
+}
+
+// Decrypt opens one chunk sealed by Encrypt.
+func (s *Suite) Decrypt(key, ciphertext []byte) ([]byte, error) {
+	return s.open(key, ciphertext, nil)
+}
+
+// seal and open protect the metadata and manifest envelopes.
+func (s *Suite) seal(key, plaintext, ad []byte) ([]byte, error) {
+	sealed, err := sealAEAD(s.newAEAD, key, plaintext, ad)
+	if err != nil || s.Version < 2 {
+		return sealed, err
+	}
+	return addCommitment(key, sealed, s.nonceSize), nil
+}
+
+func (s *Suite) open(key, ciphertext, ad []byte) ([]byte, error) {
+	if s.Version >= 2 {
+		var err error
+		if ciphertext, err = checkCommitment(key, ciphertext, s.nonceSize); err != nil {
+			return nil, err
+		}
+	}
+	return openAEAD(s.newAEAD, key, ciphertext, ad)
+}
@@ -0,0 +1,105 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// testSuite is the original v1 suite, used by tests that don't care about the cipher.
+var testSuite, _ = LookupSuite(AlgConvergent)
+
+// Known answers: key 00..1f, plaintext "codedrop known answer". Convergent and
+// team chunks are deterministic; private ones use a recorded random nonce. A
+// change here means existing drops no longer decrypt.
+var suiteKATs = []struct {
+	alg        string
+	ciphertext string
+}{
+	{AlgConvergent, "14ae878a5f723403ba929e2b8747f15188249bb3d6a2273b35e5a2abb404510db10f5b929933a490eb71270f397009af3e"},
+	{AlgPrivate, "a1dcc0c3a2d942612a5b9158309813273ad643cdb016fd33d0ea45d82730da70295f6c7064b7b1cb68a459181c36d4dce3"},
+	{AlgTeam, "188d450b2aaf869501876187bba979c74d39e100c185e4f938caa6232eb82b07fb5e015c9f10deba447c1b77365a0ffe6b"},
+	{AlgXChaChaConvergent, "14ae878a5f723403ba929e2be801639d64aefa1cc69029309a07be47cd1287dfa0b1b8717a7a45ed651b1aa59f755fd7a3c474891919624277305e6a9f"},
+	{AlgXChaChaPrivate, "7f4296884db7cb642a4fdbd244c676ae10c70242d42aa6bd14d39c1ffe01d8ea7d0cae9ae15b4caec991444aa0265d8874737f9131ce1ef2298f54ce0a"},
+	{AlgXChaChaTeam, "188d450b2aaf8695018761875219681049787e91116c9bd72ad07cc70c829694691f4275c45ab98cb19f20558d7f95db6744c388db80b4a650a5bfce76"},
+	{AlgV2Convergent, "d50d1e4cb1fd211e4c83f0a983b1cfaec0abaa2f784b934d73dd773adbad1fb114ae878a5f723403ba929e2b8747f15188249bb3d6a2273b35e5a2abb404510db10f5b929933a490eb71270f397009af3e"},
+	{AlgV2Private, "8c1587f87cf4f25f0736eec104433fdad7b2818fa711da695af0f3f66cbc026510bd6bdc6413fd2173db80549f0f8991b716a4f0e507a95a8f9e6d514e4ba60e2f0c15d319a0206d21613a0daf2a3f9073"},
+	{AlgV2Team, "ef4d76c2749105c9b1352420503c081f71f7ba4e4b71cfad15b11366f41007e1188d450b2aaf869501876187bba979c74d39e100c185e4f938caa6232eb82b07fb5e015c9f10deba447c1b77365a0ffe6b"},
+	{AlgV2XChaChaConvergent, "225028c817e52b3db28001629374e0c4333d4860d00d0dbde2cae32f5de244b314ae878a5f723403ba929e2be801639d64aefa1cc69029309a07be47cd1287dfa0b1b8717a7a45ed651b1aa59f755fd7a3c474891919624277305e6a9f"},
+	{AlgV2XChaChaPrivate, "584543239df1cc95d72eaf1cb7a6285da414a009f07c0855a1e058dd502f192ec78c676f0668a4f2716ec646d23be1e40751c3c02650e2a0720525999a9b2c86712fbb601ef75e9447c6414b8be29b0d5e7cf8e2baaa49606658eaf780"},
+	{AlgV2XChaChaTeam, "34139d0681487bec4da14ef62617999c292b79f0f9ccfd019f2d5e2cafcd1ce6188d450b2aaf8695018761875219681049787e91116c9bd72ad07cc70c829694691f4275c45ab98cb19f20558d7f95db6744c388db80b4a650a5bfce76"},
+}
+
+func TestSuiteKnownAnswers(t *testing.T) {
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	plaintext := []byte("codedrop known answer")
+
+	for _, kat := range suiteKATs {
+		suite, err := LookupSuite(kat.alg)
+		if err != nil {
+			t.Fatalf("%s: %v", kat.alg, err)
+		}
+		want, _ := hex.DecodeString(kat.ciphertext)
+
+		got, err := suite.Decrypt(key, want)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Errorf("%s: failed to decrypt known ciphertext: %v", kat.alg, err)
+		}
+
+		if suite.KeyMode != KeyPrivate {
+			if got, _ := suite.Encrypt(key, plaintext); !bytes.Equal(got, want) {
+				t.Errorf("%s: Encrypt = %x, want %x", kat.alg, got, want)
+			}
+		}
+	}
+}
+
+func TestAESSuitesMatchOriginalFormat(t *testing.T) {
+	key, _, _ := GenerateKey()
+	plaintext := []byte("existing drops must keep decrypting")
+
+	legacy, _ := Encrypt(key, plaintext)
+	suite, _ := LookupSuite(AlgConvergent)
+	if got, _ := suite.Encrypt(key, plaintext); !bytes.Equal(got, legacy) {
+		t.Errorf("v1-aes-gcm suite output differs from Encrypt")
+	}
+
+	// The team and private suites only change the nonce, so the original
+	// Decrypt still opens their chunks
+	for _, alg := range []string{AlgTeam, AlgPrivate} {
+		suite, _ = LookupSuite(alg)
+		sealed, _ := suite.Encrypt(key, plaintext)
+		if got, err := Decrypt(key, sealed); err != nil || !bytes.Equal(got, plaintext) {
+			t.Errorf("%s suite output does not open with Decrypt: %v", alg, err)
+		}
+	}
+}
+
+func TestSuitesDoNotCrossDecrypt(t *testing.T) {
+	key, _, _ := GenerateKey()
+	aes, _ := SelectSuite(CipherAESGCM, KeyPrivate)
+	xchacha, _ := SelectSuite(CipherXChaCha, KeyPrivate)
+
+	sealed, _ := SealMetadata(xchacha, key, &FileMetadata{Name: "a.txt"})
+	if _, err := OpenMetadata(xchacha, key, sealed); err != nil {
+		t.Fatalf("Failed to open XChaCha metadata: %v", err)
+	}
+	if _, err := OpenMetadata(aes, key, sealed); err == nil {
+		t.Errorf("XChaCha metadata should not open under AES-GCM")
+	}
+}
+
+func TestSuiteLookup(t *testing.T) {
+	if s, err := LookupSuite(""); err != nil || s.ID != AlgConvergent {
+		t.Errorf("Empty algorithm should mean v1-aes-gcm, got %v %v", s, err)
+	}
+	if _, err := LookupSuite("v2-rot13"); err == nil {
+		t.Errorf("Expected unknown algorithm to be rejected")
+	}
+	if _, err := SelectSuite("des", KeyConvergent); err == nil {
+		t.Errorf("Expected unknown cipher to be rejected")
+	}
+	if s, _ := SelectSuite(CipherXChaCha, KeyTeam); s.ID != AlgV2XChaChaTeam {
+		t.Errorf("Unexpected suite for xchacha/team: %s", s.ID)
+	}
+}
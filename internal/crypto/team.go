@@ -0,0 +1,34 @@
+package crypto
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// AlgTeam is convergent encryption keyed with a team secret. Pushes from the
+// same team still deduplicate, but outsiders can neither derive the key from
+// a guessed file nor confirm a guess against the ciphertext.
+const AlgTeam = "v1-aes-gcm-team"
+
+// MinTeamSecretSize rejects secrets too short to resist brute force.
+const MinTeamSecretSize = 16
+
+// DeriveTeamKey mixes the team secret into the convergent key via HKDF-SHA256.
+// The file hash acts as the salt, so every file still gets its own key.
+func DeriveTeamKey(teamSecret, fileHash []byte) ([]byte, error) {
+	if len(teamSecret) < MinTeamSecretSize {
+		return nil, fmt.Errorf("team secret too short: need at least %d bytes, got %d", MinTeamSecretSize, len(teamSecret))
+	}
+	return hkdf.Key(sha256.New, teamSecret, fileHash, "codedrop-team-key-v1", 32)
+}
+
+// TeamKeyID identifies a team secret without revealing it, so a receiver can
+// tell which secret a drop was keyed with.
+func TeamKeyID(teamSecret []byte) string {
+	mac := hmac.New(sha256.New, teamSecret)
+	mac.Write([]byte("codedrop-team-key-id-v1"))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
@@ -0,0 +1,69 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestDeriveTeamKey(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	otherSecret := []byte("fedcba9876543210fedcba9876543210")
+	fileHash := sha256.Sum256([]byte("release build"))
+
+	key, err := DeriveTeamKey(secret, fileHash[:])
+	if err != nil {
+		t.Fatalf("Failed to derive team key: %v", err)
+	}
+	again, _ := DeriveTeamKey(secret, fileHash[:])
+	if !bytes.Equal(key, again) {
+		t.Errorf("Team key derivation is not deterministic")
+	}
+
+	// Outsiders (or another team) get an unrelated key, and the plain convergent key is not it
+	outsider, _ := DeriveTeamKey(otherSecret, fileHash[:])
+	if bytes.Equal(key, outsider) || bytes.Equal(key, fileHash[:]) {
+		t.Errorf("Team key does not depend on the team secret")
+	}
+
+	if _, err := DeriveTeamKey([]byte("short"), fileHash[:]); err == nil {
+		t.Errorf("Expected a short team secret to be rejected")
+	}
+}
+
+func TestTeamKeyID(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	id := TeamKeyID(secret)
+	if len(id) != 16 || id != TeamKeyID(secret) {
+		t.Errorf("Unexpected team key ID %q", id)
+	}
+	if id == TeamKeyID([]byte("fedcba9876543210fedcba9876543210")) {
+		t.Errorf("Different secrets share a key ID")
+	}
+}
+
+func TestTeamSuiteResistsConfirmation(t *testing.T) {
+	key, _, _ := GenerateKey()
+	plaintext := []byte("guessable chunk")
+	suite, _ := LookupSuite(AlgTeam)
+
+	first, err := suite.Encrypt(key, plaintext)
+	if err != nil {
+		t.Fatalf("Encryption failed: %v", err)
+	}
+	second, _ := suite.Encrypt(key, plaintext)
+	if !bytes.Equal(first, second) {
+		t.Errorf("Keyed encryption must stay deterministic for deduplication")
+	}
+
+	// The legacy nonce is a bare hash of the chunk; the keyed one must not be
+	hash := sha256.Sum256(plaintext)
+	if bytes.Equal(first[:12], hash[:12]) {
+		t.Errorf("Keyed nonce leaks the plaintext hash")
+	}
+
+	decrypted, err := suite.Decrypt(key, first)
+	if err != nil || !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("Decrypt failed on keyed ciphertext: %v", err)
+	}
+}
@@ -14,6 +14,17 @@ type DB struct {
 	*sqlx.DB
 }
 
+// ExpiresAt is the SQL expression for when a drops row actually expires: its
+// absolute expires_at, or earlier under the idle and grace rules (push
+// --idle-ttl, --grace), or 15 minutes after its last download was handed out
+// if the burn queue never got to it. LEAST and GREATEST skip NULLs, so a drop
+// without those rules expires at expires_at. The download endpoints and the
+// garbage collector both use it, so a drop is refused and collected at the same moment.
+const ExpiresAt = `LEAST(expires_at,
+	GREATEST(created_at, not_before, last_download_at) + idle_ttl_seconds * INTERVAL '1 second',
+	first_download_at + grace_seconds * INTERVAL '1 second',
+	exhausted_at + INTERVAL '15 minutes')`
+
 // NewConnection creates a new database connection
 func NewConnection() (*DB, error) {
 	// We get connection details from environment variables (12-Factor App methodology)
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+// TestExpiresAt evaluates the ExpiresAt expression over literal drops rows.
+// It needs the Postgres from docker-compose and is skipped without it.
+func TestExpiresAt(t *testing.T) {
+	conn, err := NewConnection()
+	if err != nil {
+		t.Skipf("Postgres not available: %v", err)
+	}
+	defer conn.Close()
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		ts := base.Add(d)
+		return &ts
+	}
+	seconds := func(d time.Duration) *int {
+		s := int(d / time.Second)
+		return &s
+	}
+
+	tests := []struct {
+		name          string
+		expiresAt     time.Time
+		notBefore     *time.Time
+		lastDownload  *time.Time
+		idleTTL       *int
+		firstDownload *time.Time
+		grace         *int
+		exhaustedAt   *time.Time
+		want          time.Time
+	}{
+		{name: "no rules", expiresAt: *at(24 * time.Hour), want: *at(24 * time.Hour)},
+		{name: "idle since push", expiresAt: *at(24 * time.Hour), idleTTL: seconds(30 * time.Minute), want: *at(30 * time.Minute)},
+		{name: "idle restarted by a download", expiresAt: *at(24 * time.Hour), idleTTL: seconds(30 * time.Minute), lastDownload: at(time.Hour), want: *at(90 * time.Minute)},
+		{name: "idle from not before", expiresAt: *at(24 * time.Hour), idleTTL: seconds(30 * time.Minute), notBefore: at(2 * time.Hour), want: *at(150 * time.Minute)},
+		{name: "idle capped by expiry", expiresAt: *at(time.Hour), idleTTL: seconds(2 * time.Hour), want: *at(time.Hour)},
+		{name: "grace not started", expiresAt: *at(24 * time.Hour), grace: seconds(10 * time.Minute), want: *at(24 * time.Hour)},
+		{name: "grace after first download", expiresAt: *at(24 * time.Hour), grace: seconds(10 * time.Minute), firstDownload: at(time.Hour), want: *at(70 * time.Minute)},
+		{name: "exhausted", expiresAt: *at(24 * time.Hour), exhaustedAt: at(time.Hour), want: *at(75 * time.Minute)},
+		{name: "earliest rule wins", expiresAt: *at(24 * time.Hour), idleTTL: seconds(3 * time.Hour), grace: seconds(10 * time.Minute), firstDownload: at(time.Hour), lastDownload: at(time.Hour), want: *at(70 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got time.Time
+			err := conn.QueryRow(`
+				SELECT `+ExpiresAt+` FROM (SELECT
+					$1::timestamptz AS expires_at, $2::timestamptz AS created_at, $3::timestamptz AS not_before,
+					$4::timestamptz AS last_download_at, $5::int AS idle_ttl_seconds,
+					$6::timestamptz AS first_download_at, $7::int AS grace_seconds, $8::timestamptz AS exhausted_at) drops`,
+				tt.expiresAt, base, tt.notBefore, tt.lastDownload, tt.idleTTL, tt.firstDownload, tt.grace, tt.exhaustedAt).Scan(&got)
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Expected the drop to expire at %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -6,9 +6,7 @@ CREATE TABLE IF NOT EXISTS drops (
     expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
     max_downloads INT NOT NULL,
     current_downloads INT DEFAULT 0,
-    file_name TEXT NOT NULL,
     file_size BIGINT NOT NULL,
-    mime_type TEXT,
     encryption_salt TEXT NOT NULL, -- Used to derive the key on the client side
     is_deleted BOOLEAN DEFAULT FALSE
 );
@@ -24,3 +22,78 @@ CREATE TABLE IF NOT EXISTS chunks (
 
 CREATE INDEX IF NOT EXISTS idx_drops_expires_at
 ON drops(expires_at);    -- this makes it faster to find expired drops for cleanup
+
+-- The encrypted manifest is opaque to the server; the CLI authenticates it before downloading.
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS manifest BYTEA;
+
+-- File name, MIME type and other descriptive metadata are encrypted client-side.
+-- The server keeps only what it needs for enforcement and drops the plaintext columns.
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS metadata BYTEA;
+ALTER TABLE drops DROP COLUMN IF EXISTS file_name;
+ALTER TABLE drops DROP COLUMN IF EXISTS mime_type;
+
+-- How the drop was encrypted (convergent, private, ...). encryption_salt used to double as this.
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS algorithm TEXT NOT NULL DEFAULT 'v1-aes-gcm';
+
+-- Identifies the team secret a team-keyed drop was derived from (never the secret itself).
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS key_id TEXT;
+
+-- Drop keys wrapped to recipient public keys (push --to). Opaque to the server.
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS wrapped_keys BYTEA;
+
+-- Short codes (push --code). The server maps a small nameplate to a drop and answers
+-- SPAKE2 claims; the URL fragment stays sealed under a key only the code's words derive.
+CREATE TABLE IF NOT EXISTS short_codes (
+    nameplate INT PRIMARY KEY,
+    drop_id UUID NOT NULL REFERENCES drops(id) ON DELETE CASCADE,
+    kdf_params TEXT NOT NULL,  -- Argon2id parameters and salt for stretching the words
+    verifier BYTEA NOT NULL,   -- SPAKE2 password scalar
+    fragment BYTEA NOT NULL,   -- URL fragment, sealed by the sender
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Inbound requests (codedrop request). The slot is created empty with the requester's
+-- public key; push --into fills in metadata and wrapped keys, so metadata stays NULL until then.
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS requested_for TEXT;
+
+-- Owner tokens (returned once by POST /drop) authorize reshare. Only a SHA-256 is stored.
+-- A reshared drop gets its own chunks rows for the same hashes, so GC's reference count covers it.
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS owner_token_hash TEXT;
+
+-- Access grants (push --recipients): one link per person under a single drop. The ID is
+-- derived from the grant key in the link; the drop key is stored wrapped under that key.
+-- Downloads are counted per grant in Redis, and a revoked grant loses its wrapped key.
+CREATE TABLE IF NOT EXISTS grants (
+    id TEXT PRIMARY KEY,
+    drop_id UUID NOT NULL REFERENCES drops(id) ON DELETE CASCADE,
+    label TEXT NOT NULL,
+    wrapped_key BYTEA,
+    max_downloads INT NOT NULL,
+    last_download_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE(drop_id, label)
+);
+
+-- A drop with grants can only be downloaded through one of them.
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS grants_only BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Embargo (push --not-before): metadata and chunks answer 425 until this time. NULL means no embargo.
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS not_before TIMESTAMP WITH TIME ZONE;
+
+-- Lifecycle rules (push --idle-ttl, --grace) on top of the absolute expires_at. A drop also
+-- expires idle_ttl_seconds after it was pushed, opened or last downloaded, and grace_seconds
+-- after its first completed download. db.ExpiresAt combines them; NULL means no such rule.
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS idle_ttl_seconds INT;
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS grace_seconds INT;
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS last_download_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS first_download_at TIMESTAMP WITH TIME ZONE;
+
+-- Burn after reading. When a drop hands out its last permitted download, exhausted_at is set and
+-- burn_after records how many downloads were started. Once that many have been served to the
+-- last chunk, the drop is queued for the garbage collector; otherwise it expires 15 minutes on.
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS exhausted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS burn_after INT;
+
+-- The sender filling a request slot gets its own upload token; the slot's owner token stays with the requester.
+ALTER TABLE drops ADD COLUMN IF NOT EXISTS upload_token_hash TEXT;
@@ -66,7 +66,7 @@ func (s *Store) DownloadChunk(key string) ([]byte, error) {
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to download chunk %s: %w", err)
+		return nil, fmt.Errorf("failed to download chunk %s: %w", key, err)
 	}
 	defer resp.Body.Close()
 
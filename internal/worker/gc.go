@@ -6,20 +6,24 @@ import (
 	"log"
 	"time"
 
+	"github.com/sumanthd032/codedrop/internal/cache"
 	"github.com/sumanthd032/codedrop/internal/db"
 	"github.com/sumanthd032/codedrop/internal/store"
 )
 
-// GarbageCollector handles the background cleanup of expired drops
+// GarbageCollector handles the background cleanup of expired drops, and of
+// used-up drops queued for burn-after-reading
 type GarbageCollector struct {
 	DB    *db.DB
 	Store *store.Store
+	Cache *cache.RedisClient
 }
 
-func NewGarbageCollector(db *db.DB, store *store.Store) *GarbageCollector {
+func NewGarbageCollector(db *db.DB, store *store.Store, cacheClient *cache.RedisClient) *GarbageCollector {
 	return &GarbageCollector{
 		DB:    db,
 		Store: store,
+		Cache: cacheClient,
 	}
 }
 
@@ -30,6 +34,9 @@ func (gc *GarbageCollector) Start(ctx context.Context, interval time.Duration) {
 
 	log.Printf("Garbage Collector started. Running every %v\n", interval)
 
+	// Burned drops don't wait for the next sweep
+	go gc.drainBurnQueue(ctx)
+
 	for {
 		select {
 		case <-ctx.Done(): // Graceful shutdown
@@ -43,9 +50,9 @@ func (gc *GarbageCollector) Start(ctx context.Context, interval time.Duration) {
 
 // sweep does the actual work of finding and deleting old drops
 func (gc *GarbageCollector) sweep() {
-	// 1. Find all expired drops
+	// 1. Find all expired drops, including those expired early by their idle or grace rule
 	// We only select the ID. We don't need the rest of the metadata.
-	rows, err := gc.DB.Query("SELECT id FROM drops WHERE expires_at < NOW()")
+	rows, err := gc.DB.Query("SELECT id FROM drops WHERE " + db.ExpiresAt + " < NOW()")
 	if err != nil {
 		log.Printf("[GC Error] Failed to query expired drops: %v", err)
 		return
@@ -74,6 +81,25 @@ func (gc *GarbageCollector) sweep() {
 	}
 }
 
+// drainBurnQueue deletes drops as soon as the API queues them, once their last
+// permitted download has completed. A drop queued twice is simply gone the second time.
+func (gc *GarbageCollector) drainBurnQueue(ctx context.Context) {
+	for ctx.Err() == nil {
+		dropID, err := gc.Cache.NextBurn(ctx, 5*time.Second)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("[GC Error] Failed to read the burn queue: %v", err)
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+		if dropID != "" {
+			log.Printf("GC burning used-up drop %s\n", dropID)
+			gc.deleteDrop(dropID)
+		}
+	}
+}
+
 // deleteDrop wipes a single drop from S3 and Postgres safely using Reference Counting
 func (gc *GarbageCollector) deleteDrop(dropID string) {
 	// A. Find all chunk hashes associated with this drop
@@ -84,10 +110,13 @@ func (gc *GarbageCollector) deleteDrop(dropID string) {
 	}
 	defer rows.Close()
 
+	// A hash can appear more than once (repeated content), but is reclaimed only once
 	var hashes []string
+	seen := make(map[string]bool)
 	for rows.Next() {
 		var hash string
-		if err := rows.Scan(&hash); err == nil {
+		if err := rows.Scan(&hash); err == nil && !seen[hash] {
+			seen[hash] = true
 			hashes = append(hashes, hash)
 		}
 	}
@@ -102,7 +131,9 @@ func (gc *GarbageCollector) deleteDrop(dropID string) {
 
 	// C. Safely delete from S3 (Reference Counting)
 	for _, hash := range hashes {
-		// Check if any OTHER active drops are still using this exact chunk
+		// Check if any OTHER active drops are still using this exact chunk.
+		// Reshared drops (codedrop reshare) hold their own rows for the same hashes,
+		// so an expired original never takes a live reshare's chunks with it.
 		var count int
 		err := gc.DB.QueryRow("SELECT COUNT(*) FROM chunks WHERE chunk_hash = $1", hash).Scan(&count)
 		
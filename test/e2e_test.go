@@ -3,13 +3,21 @@ package test
 import (
 	"bytes"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/sumanthd032/codedrop/internal/db"
+	"github.com/sumanthd032/codedrop/internal/store"
 )
 
 // Configuration
@@ -124,6 +132,233 @@ func TestEndToEndFlows(t *testing.T) {
 		}
 	})
 
+	t.Run("Optimization: Reshare Without Re-upload", func(t *testing.T) {
+		filename := "test_reshare.txt"
+		content := []byte("Reshare Test")
+		createFile(t, filename, content)
+		defer os.Remove(filename)
+		defer os.Remove("downloaded_" + filename)
+
+		// Download the original once; it keeps a view, so it isn't burned
+		output := runCLI(t, "push", filename, "--max-views", "2")
+		url := extractURL(t, output)
+		runCLI(t, "pull", url)
+		os.Remove("downloaded_" + filename)
+
+		// The owner token saved by push is enough for a fresh link
+		output = runCLI(t, "reshare", url, "--expire", "10m", "--max-views", "1")
+		newURL := extractURL(t, output)
+		if newURL == url {
+			t.Fatalf("Reshare returned the original URL")
+		}
+
+		runCLI(t, "pull", newURL)
+		if hashFile(t, filename) != hashFile(t, "downloaded_"+filename) {
+			t.Fatalf("Reshared drop does not match the original file")
+		}
+	})
+
+	t.Run("Optimization: Reshare Outlives a Burned Original", func(t *testing.T) {
+		filename := "test_reshare_burn.txt"
+		createFile(t, filename, []byte(fmt.Sprintf("Reshare Burn Test %d", time.Now().UnixNano())))
+		defer os.Remove(filename)
+		defer os.Remove("downloaded_" + filename)
+
+		output := runCLI(t, "push", filename, "--max-views", "1")
+		url := extractURL(t, output)
+		output = runCLI(t, "reshare", url, "--expire", "10m", "--max-views", "1")
+		newURL := extractURL(t, output)
+
+		// Using up the original burns it, but the chunks it shares stay
+		runCLI(t, "pull", url)
+		os.Remove("downloaded_" + filename)
+		waitForBurn(t, url, nil) // The reshare still references every object
+
+		// A burned drop is gone, so it can no longer be reshared
+		out, err := exec.Command(cliPath, "reshare", url, "--max-views", "1").CombinedOutput()
+		if err == nil {
+			t.Fatalf("A burned drop was reshared")
+		}
+		if !strings.Contains(string(out), "not found") {
+			t.Fatalf("Unexpected error resharing a burned drop: %s", out)
+		}
+
+		runCLI(t, "pull", newURL)
+		if hashFile(t, filename) != hashFile(t, "downloaded_"+filename) {
+			t.Fatalf("Reshared drop does not match the original file")
+		}
+	})
+
+	t.Run("Security: Per-Recipient Grants and Revocation", func(t *testing.T) {
+		filename := "test_grants.txt"
+		content := []byte("Grant Test")
+		createFile(t, filename, content)
+		defer os.Remove(filename)
+		defer os.Remove("downloaded_" + filename)
+
+		output := runCLI(t, "push", filename, "--recipients", "alice,bob", "--max-views", "1")
+		aliceURL := regexp.MustCompile(`alice\s+:\s+(http://\S+)`).FindStringSubmatch(output)
+		bobURL := regexp.MustCompile(`bob\s+:\s+(http://\S+)`).FindStringSubmatch(output)
+		if aliceURL == nil || bobURL == nil {
+			t.Fatalf("Failed to extract grant URLs from output:\n%s", output)
+		}
+
+		// Each link has its own limit: alice using hers leaves bob's intact
+		runCLI(t, "pull", aliceURL[1])
+		if hashFile(t, filename) != hashFile(t, "downloaded_"+filename) {
+			t.Fatalf("Grant download does not match the original file")
+		}
+		os.Remove("downloaded_" + filename)
+
+		runCLI(t, "revoke", bobURL[1])
+		out, err := exec.Command(cliPath, "pull", bobURL[1]).CombinedOutput()
+		if err == nil {
+			t.Fatalf("Revoked link still downloads")
+		}
+		if !strings.Contains(string(out), "revoked") {
+			t.Fatalf("Unexpected error for a revoked link: %s", out)
+		}
+	})
+
+	t.Run("Security: Inbound Request Is Filled Once", func(t *testing.T) {
+		filename := "test_request.txt"
+		createFile(t, filename, []byte(fmt.Sprintf("Request Test %d", time.Now().UnixNano())))
+		defer os.Remove(filename)
+		defer os.Remove("downloaded_" + filename)
+
+		identity := filepath.Join(t.TempDir(), "identity")
+		runCLI(t, "keygen", "--output", identity)
+		output := runCLI(t, "request", "--identity", identity, "--no-wait", "--expire", "10m")
+		uploadURL := regexp.MustCompile(`Upload URL\s+:\s+(http://\S+)`).FindStringSubmatch(output)
+		dropURL := regexp.MustCompile(`Pull with: codedrop pull (http://\S+)`).FindStringSubmatch(output)
+		if uploadURL == nil || dropURL == nil {
+			t.Fatalf("Failed to extract request URLs from output:\n%s", output)
+		}
+
+		// Two senders race for the slot: exactly one fill wins
+		results := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				_, err := exec.Command(cliPath, "push", "--into", uploadURL[1], filename).CombinedOutput()
+				results <- err
+			}()
+		}
+		filled := 0
+		for i := 0; i < 2; i++ {
+			if <-results == nil {
+				filled++
+			}
+		}
+		if filled != 1 {
+			t.Fatalf("Expected exactly one sender to fill the request, %d did", filled)
+		}
+
+		// Once filled, the slot refuses further uploads
+		out, err := exec.Command(cliPath, "push", "--into", uploadURL[1], filename).CombinedOutput()
+		if err == nil {
+			t.Fatalf("A filled request accepted a second upload")
+		}
+		if !strings.Contains(string(out), "filled") {
+			t.Fatalf("Unexpected error for a filled request: %s", out)
+		}
+
+		// Only the requester's identity opens the drop
+		if _, err := exec.Command(cliPath, "pull", dropURL[1]).CombinedOutput(); err == nil {
+			t.Fatalf("Request drop downloaded without the requester's identity")
+		}
+		runCLI(t, "pull", "--identity", identity, dropURL[1])
+		if hashFile(t, filename) != hashFile(t, "downloaded_"+filename) {
+			t.Fatalf("Request drop does not match the original file")
+		}
+	})
+
+	t.Run("Security: Short Codes Need the Owner Token", func(t *testing.T) {
+		filename := "test_code.txt"
+		content := []byte("Short Code Test")
+		createFile(t, filename, content)
+		defer os.Remove(filename)
+		defer os.Remove("downloaded_" + filename)
+
+		output := runCLI(t, "push", filename, "--code", "--max-views", "2")
+		url := extractURL(t, output)
+		code := regexp.MustCompile(`Short Code\s+:\s+(\S+)`).FindStringSubmatch(output)
+		if code == nil {
+			t.Fatalf("Failed to extract short code from output:\n%s", output)
+		}
+		runCLI(t, "pull", code[1])
+		if hashFile(t, filename) != hashFile(t, "downloaded_"+filename) {
+			t.Fatalf("Short code download does not match the original file")
+		}
+
+		// Nobody else can register a code for the drop
+		dropID := dropIDOf(t, url)
+		for token, want := range map[string]int{"": http.StatusUnauthorized, "not-the-owner": http.StatusForbidden} {
+			req, _ := http.NewRequest(http.MethodPost, serverURL+"/api/v1/code", strings.NewReader(`{"drop_id":"`+dropID+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if token != "" {
+				req.Header.Set("X-Owner-Token", token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to create short code: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != want {
+				t.Fatalf("Expected status %d creating a short code with owner token %q, got %d", want, token, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("Security: Embargoed Drop Opens at Not-Before", func(t *testing.T) {
+		filename := "test_embargo.txt"
+		content := []byte("Embargo Test")
+		createFile(t, filename, content)
+		defer os.Remove(filename)
+		defer os.Remove("downloaded_" + filename)
+
+		output := runCLI(t, "push", filename, "--not-before", "3s")
+		url := extractURL(t, output)
+
+		// Too early: refused without spending the single view
+		out, err := exec.Command(cliPath, "pull", url).CombinedOutput()
+		if err == nil {
+			t.Fatalf("Embargoed drop downloaded before its time")
+		}
+		if !strings.Contains(string(out), "not available until") {
+			t.Fatalf("Unexpected error for an embargoed drop: %s", out)
+		}
+
+		runCLI(t, "pull", "--wait", url)
+		if hashFile(t, filename) != hashFile(t, "downloaded_"+filename) {
+			t.Fatalf("Embargoed drop does not match the original file")
+		}
+	})
+
+	t.Run("Security: Burn After Reading", func(t *testing.T) {
+		filename := "test_burn.txt"
+		createFile(t, filename, []byte(fmt.Sprintf("Burn Test %d", time.Now().UnixNano())))
+		defer os.Remove(filename)
+		defer os.Remove("downloaded_" + filename)
+
+		output := runCLI(t, "push", filename, "--max-views", "2")
+		url := extractURL(t, output)
+		objects := chunkObjects(t, url)
+
+		// The first of two downloads leaves the drop in place, and its chunks
+		// stay closed to anyone without a counted download
+		runCLI(t, "pull", url)
+		os.Remove("downloaded_" + filename)
+		time.Sleep(time.Second)
+		if status := chunkStatus(t, url); status != http.StatusUnauthorized {
+			t.Fatalf("Expected a chunk request without a download token to be refused, got status %d", status)
+		}
+
+		// The last one deletes the drop and its chunks well before it expires,
+		// both the rows and the objects in storage
+		runCLI(t, "pull", url)
+		waitForBurn(t, url, objects)
+	})
+
 	t.Run("Security: Zero-Knowledge Key Tampering", func(t *testing.T) {
 		filename := "test_tamper.txt"
 		createFile(t, filename, []byte("Secret Data"))
@@ -197,6 +432,80 @@ func extractURL(t *testing.T, output string) string {
 	return matches[1]
 }
 
+// chunkStatus fetches the first chunk of the drop behind a share URL without
+// a download token and returns the HTTP status: 401 while the chunk exists,
+// 404 once it is deleted.
+func chunkStatus(t *testing.T, url string) int {
+	resp, err := http.Get(serverURL + "/api/v1/drop/" + dropIDOf(t, url) + "/chunk/0")
+	if err != nil {
+		t.Fatalf("Failed to fetch chunk: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// chunkObjects returns the storage keys of the drop's chunks, read from the
+// server's Postgres. Unique content keeps them from being shared with other drops.
+func chunkObjects(t *testing.T, url string) []string {
+	conn, err := db.NewConnection()
+	if err != nil {
+		t.Fatalf("Failed to connect to Postgres: %v", err)
+	}
+	defer conn.Close()
+
+	var hashes []string
+	if err := conn.Select(&hashes, "SELECT DISTINCT chunk_hash FROM chunks WHERE drop_id = $1", dropIDOf(t, url)); err != nil {
+		t.Fatalf("Failed to list the drop's chunks: %v", err)
+	}
+	if len(hashes) == 0 {
+		t.Fatalf("Drop has no chunks")
+	}
+	var objects []string
+	for _, hash := range hashes {
+		objects = append(objects, "chunks/"+hash)
+	}
+	return objects
+}
+
+// waitForBurn waits for a used-up drop's chunks to be deleted, and with them
+// their objects from storage
+func waitForBurn(t *testing.T, url string, objects []string) {
+	s3, err := store.NewS3Store()
+	if err != nil {
+		t.Fatalf("Failed to connect to storage: %v", err)
+	}
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(200 * time.Millisecond) {
+		if chunkStatus(t, url) == http.StatusNotFound && !anyObjectStored(t, s3, objects) {
+			return
+		}
+	}
+	t.Fatalf("Drop was not burned within 10 seconds of its last download")
+}
+
+// anyObjectStored reports whether any of the objects is still in storage
+func anyObjectStored(t *testing.T, s3 *store.Store, objects []string) bool {
+	for _, key := range objects {
+		_, err := s3.DownloadChunk(key)
+		var missing *types.NoSuchKey
+		if err == nil {
+			return true
+		}
+		if !errors.As(err, &missing) {
+			t.Fatalf("Failed to check storage for %s: %v", key, err)
+		}
+	}
+	return false
+}
+
+// dropIDOf extracts the drop ID from a share URL
+func dropIDOf(t *testing.T, url string) string {
+	dropID := regexp.MustCompile(`/drop/([^#/]+)`).FindStringSubmatch(url)
+	if dropID == nil {
+		t.Fatalf("No drop ID in URL %s", url)
+	}
+	return dropID[1]
+}
+
 func isServerUp() bool {
 	// Simple curl check
 	cmd := exec.Command("curl", "-s", serverURL+"/health")